@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/avos-io/panic-parse/core"
+)
+
+// runDiff implements the diff subcommand, which prints how the goroutines of
+// two dumps differ. Each dump can be a panic log, a goroutine dump or a
+// goroutine profile in either pprof format.
+func runDiff(args []string, stdout io.Writer) error {
+	if len(args) != 2 {
+		return errors.New("usage: diff <before> <after>")
+	}
+
+	before, err := readDump(args[0])
+	if err != nil {
+		return err
+	}
+
+	after, err := readDump(args[1])
+	if err != nil {
+		return err
+	}
+
+	printDiff(stdout, core.Diff(before, after))
+	return nil
+}
+
+func readDump(path string) (*core.Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	event, err := core.ParseProfile(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return event, nil
+}
+
+func printDiff(w io.Writer, diff *core.DumpDiff) {
+	sections := []struct {
+		name   string
+		groups []*core.GroupDiff
+	}{
+		{"new", diff.New},
+		{"grown", diff.Grown},
+		{"vanished", diff.Vanished},
+		{"shrunk", diff.Shrunk},
+	}
+
+	printed := false
+	for _, section := range sections {
+		if len(section.groups) == 0 {
+			continue
+		}
+
+		if printed {
+			fmt.Fprintln(w)
+		}
+		printed = true
+
+		fmt.Fprintf(w, "%s:\n", section.name)
+		for _, group := range section.groups {
+			state := ""
+			if group.Goroutine.State != "" {
+				state = " [" + group.Goroutine.State + "]"
+			}
+
+			fmt.Fprintf(w, "  %+d (%d -> %d)%s\n", group.Delta(), group.Before, group.After, state)
+			for _, line := range strings.Split(strings.TrimSuffix(group.Signature, "\n"), "\n") {
+				fmt.Fprintf(w, "    %s\n", line)
+			}
+		}
+	}
+
+	if !printed {
+		fmt.Fprintln(w, "no differences")
+	}
+}
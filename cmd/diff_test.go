@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunDiff(t *testing.T) {
+	dir := t.TempDir()
+
+	before := filepath.Join(dir, "before.txt")
+	require.NoError(t, os.WriteFile(before, []byte(`goroutine 1 [select]:
+main.worker()
+	/app/main.go:20 +0x1
+
+goroutine 2 [IO wait]:
+main.poller()
+	/app/main.go:30 +0x1
+`), 0o644))
+
+	after := filepath.Join(dir, "after.txt")
+	require.NoError(t, os.WriteFile(after, []byte(`goroutine 1 [select]:
+main.worker()
+	/app/main.go:20 +0x1
+
+goroutine 7 [select]:
+main.worker()
+	/app/main.go:20 +0x1
+
+goroutine 8 [chan send]:
+main.leak()
+	/app/main.go:50 +0x1
+`), 0o644))
+
+	var out strings.Builder
+	require.NoError(t, runDiff([]string{before, after}, &out))
+
+	assert.Equal(t, `new:
+  +1 (0 -> 1) [chan send]
+    main.leak /app/main.go:50
+
+grown:
+  +1 (1 -> 2) [select]
+    main.worker /app/main.go:20
+
+vanished:
+  -1 (1 -> 0) [IO wait]
+    main.poller /app/main.go:30
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, runDiff([]string{before, before}, &out))
+	assert.Equal(t, "no differences\n", out.String())
+
+	assert.Error(t, runDiff([]string{before}, &out))
+	assert.Error(t, runDiff([]string{before, filepath.Join(dir, "missing.txt")}, &out))
+}
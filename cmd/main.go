@@ -7,7 +7,7 @@ import (
 	"strings"
 	"time"
 
-	panicparse "github.com/avos-io/panic-parse"
+	panicsentry "github.com/avos-io/panic-parse/sentry"
 	sentry "github.com/getsentry/sentry-go"
 	"github.com/mitchellh/panicwrap"
 )
@@ -20,6 +20,14 @@ const (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		if err := runDiff(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		return
+	}
+
 	if wrap {
 		exitStatus, err := panicwrap.BasicWrap(panicHandler)
 		if err != nil {
@@ -85,7 +93,7 @@ func panicHandler(output string) {
 	// Use sync transport since we're dying anyway
 	initSentry(true)
 
-	event := panicparse.Parse(strings.NewReader(output))
+	event := panicsentry.Parse(strings.NewReader(output))
 	event.Extra["panic"] = output
 
 	json, _ := json.MarshalIndent(event, "", "  ")
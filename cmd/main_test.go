@@ -7,7 +7,7 @@ import (
 	"testing"
 	"time"
 
-	panicparse "github.com/avos-io/panic-parse"
+	panicsentry "github.com/avos-io/panic-parse/sentry"
 	sentry "github.com/getsentry/sentry-go"
 	"github.com/rs/zerolog/log"
 )
@@ -19,7 +19,7 @@ func TestPanicParse(t *testing.T) {
 	defer sentry.Flush(time.Second * 5)
 
 	for i, data := range testData {
-		event := panicparse.Parse(strings.NewReader(data))
+		event := panicsentry.Parse(strings.NewReader(data))
 		event.Extra["panic"] = data
 		event.Environment = "test"
 		event.Tags["iteration"] = fmt.Sprintf("%d", i)
@@ -0,0 +1,127 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Arg is an argument word printed in a traceback, or a group of words for a
+// struct, array, string, slice or interface argument, which Go prints in
+// braces.
+type Arg struct {
+	Value uint64
+	// Group holds the members of a braced argument. It is nil for a
+	// single word.
+	Group []*Arg
+	// Inaccurate is set when Go printed the word with a trailing "?" as
+	// it may no longer hold the value that was passed.
+	Inaccurate bool
+	// Elided is set for the "..." Go prints in place of arguments it
+	// truncated.
+	Elided bool
+}
+
+// IsGroup reports whether the argument is a braced group of words.
+func (a *Arg) IsGroup() bool {
+	return a.Group != nil
+}
+
+// String formats the argument as Go prints it in a traceback.
+func (a *Arg) String() string {
+	switch {
+	case a.Elided:
+		return "..."
+	case a.IsGroup():
+		return "{" + formatArgs(a.Group) + "}"
+	case a.Inaccurate:
+		return fmt.Sprintf("%#x?", a.Value)
+	default:
+		return fmt.Sprintf("%#x", a.Value)
+	}
+}
+
+func formatArgs(args []*Arg) string {
+	words := make([]string, len(args))
+	for i, arg := range args {
+		words[i] = arg.String()
+	}
+	return strings.Join(words, ", ")
+}
+
+var errUnbalancedArgs = errors.New("unbalanced braces")
+
+// parseArgs parses the text between the parentheses of a traceback frame.
+// Words that cannot be parsed are skipped and the first problem found is
+// returned alongside the arguments that could be parsed.
+func parseArgs(s string) ([]*Arg, error) {
+	args := []*Arg{}
+	stack := [][]*Arg{}
+	var firstErr error
+	fail := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	for _, word := range strings.Split(s, ",") {
+		word = strings.TrimSpace(word)
+
+		for strings.HasPrefix(word, "{") {
+			stack = append(stack, args)
+			args = []*Arg{}
+			word = word[1:]
+		}
+
+		closing := 0
+		for strings.HasSuffix(word, "}") {
+			closing++
+			word = word[:len(word)-1]
+		}
+
+		switch {
+		case word == "":
+			// Empty argument list or group
+		case word == "...":
+			args = append(args, &Arg{Elided: true})
+		default:
+			arg := &Arg{}
+			if strings.HasSuffix(word, "?") {
+				arg.Inaccurate = true
+				word = word[:len(word)-1]
+			}
+
+			value, err := strconv.ParseUint(word, 0, 64)
+			if err != nil {
+				fail(err)
+				break
+			}
+			arg.Value = value
+			args = append(args, arg)
+		}
+
+		for ; closing > 0; closing-- {
+			if len(stack) == 0 {
+				fail(errUnbalancedArgs)
+				break
+			}
+
+			group := &Arg{Group: args}
+			args = append(stack[len(stack)-1], group)
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	// Close any groups left open
+	if len(stack) > 0 {
+		fail(errUnbalancedArgs)
+		for len(stack) > 0 {
+			group := &Arg{Group: args}
+			args = append(stack[len(stack)-1], group)
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	return args, firstErr
+}
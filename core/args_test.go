@@ -0,0 +1,66 @@
+package core_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/avos-io/panic-parse/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArguments(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(`panic: oh my god
+
+goroutine 86 [running]:
+github.com/avos-io/iona/lindisfarne/internal/endpoints.(*Server).ReportDynamicInfo(0x58?, {0x1419348, 0xc0004924b0}, 0x2?)
+	/home/jon/source/iona/lindisfarne/internal/endpoints/endpoints.go:868 +0x386
+main.nested({{0x1, 0x2?}, {...}}, 0x3, ...)
+	/app/main.go:10 +0x1d
+main.inlined(...)
+	/app/main.go:20
+main.main()
+	/app/main.go:30 +0x2d`))
+	require.NoError(t, err)
+	require.Empty(t, event.Diagnostics)
+
+	frames := event.Threads[0].Frames
+	require.Len(t, frames, 4)
+
+	args := frames[0].Arguments
+	require.Len(t, args, 3)
+	assert.Equal(t, &core.Arg{Value: 0x58, Inaccurate: true}, args[0])
+	assert.True(t, args[1].IsGroup())
+	assert.Equal(t, []*core.Arg{{Value: 0x1419348}, {Value: 0xc0004924b0}}, args[1].Group)
+	assert.Equal(t, &core.Arg{Value: 0x2, Inaccurate: true}, args[2])
+
+	args = frames[1].Arguments
+	require.Len(t, args, 3)
+	require.Len(t, args[0].Group, 2)
+	assert.Equal(t, "{0x1, 0x2?}", args[0].Group[0].String())
+	assert.Equal(t, []*core.Arg{{Elided: true}}, args[0].Group[1].Group)
+	assert.Equal(t, &core.Arg{Value: 0x3}, args[1])
+	assert.True(t, args[2].Elided)
+	assert.Equal(t, "{{0x1, 0x2?}, {...}}", args[0].String())
+
+	assert.Equal(t, []*core.Arg{{Elided: true}}, frames[2].Arguments)
+	assert.Empty(t, frames[3].Arguments)
+}
+
+func TestArgumentsDiagnostics(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(`panic: oh no
+
+goroutine 1 [running]:
+main.main(0x1, {0xzz, 0x2)
+	/app/main.go:30 +0x2d`))
+	require.NoError(t, err)
+
+	require.Len(t, event.Diagnostics, 1)
+	assert.Contains(t, event.Diagnostics[0].Error(), "failed to parse arguments")
+	assert.Equal(t, 4, event.Diagnostics[0].Line)
+
+	args := event.Threads[0].Frames[0].Arguments
+	require.Len(t, args, 2)
+	assert.Equal(t, uint64(1), args[0].Value)
+	assert.Equal(t, []*core.Arg{{Value: 0x2}}, args[1].Group)
+}
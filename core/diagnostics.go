@@ -0,0 +1,54 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	errFileWithoutFunc = errors.New("file line without a preceding function")
+	errUnknownField    = errors.New("unknown field")
+	errUnrecognized    = errors.New("unrecognized line")
+)
+
+// ParseError records a line of input that the parser could not fully
+// understand.
+type ParseError struct {
+	// Line is the 1-based line number in the input.
+	Line int
+	// Raw is the input line as read.
+	Raw string
+	// State is the parser state the line was read in.
+	State string
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d (%s): %v: %q", e.Line, e.State, e.Err, e.Raw)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Diagnostics lists the anomalies seen while parsing an event, in input
+// order. An event with diagnostics was only partially understood.
+type Diagnostics []*ParseError
+
+func (s state) String() string {
+	switch s {
+	case stateInit:
+		return "init"
+	case stateFatalError:
+		return "fatal error"
+	case statePanic:
+		return "panic"
+	case stateSignal:
+		return "signal"
+	case stateStackFunc:
+		return "stack func"
+	case stateStackFile:
+		return "stack file"
+	}
+	return fmt.Sprintf("state(%d)", int(s))
+}
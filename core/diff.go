@@ -0,0 +1,160 @@
+package core
+
+import (
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DumpDiff is the difference between two goroutine dumps, with goroutines
+// grouped by stack signature. Each list is sorted by the size of the change,
+// largest first.
+type DumpDiff struct {
+	New      []*GroupDiff
+	Vanished []*GroupDiff
+	Grown    []*GroupDiff
+	Shrunk   []*GroupDiff
+}
+
+// GroupDiff compares the goroutines sharing a stack signature in two dumps.
+type GroupDiff struct {
+	Signature string
+
+	// Goroutine is one of the goroutines in the group, taken from the
+	// second dump unless the group vanished.
+	Goroutine *Goroutine
+
+	Before int
+	After  int
+}
+
+// Delta returns how many goroutines the group gained, or lost if negative.
+func (g *GroupDiff) Delta() int {
+	return g.After - g.Before
+}
+
+// Diff compares the goroutines of two dumps of the same process, such as
+// goroutine profiles taken before and after a load test, to find where
+// goroutines leaked. Stacks of aggregated profiles count for as many
+// goroutines as share them.
+//
+// Aggregated debug=1 profiles can be compared with full dumps, but they don't
+// print creators and do print the runtime internals that full dumps hide. If
+// either dump is aggregated, stacks are therefore matched without both, and
+// Signature is the signature of the stack without them.
+func Diff(a, b *Event) *DumpDiff {
+	groups := map[string]*GroupDiff{}
+	order := []string{}
+
+	aggregated := isAggregated(a) || isAggregated(b)
+
+	group := func(g *Goroutine) *GroupDiff {
+		signature := g.Signature()
+		if aggregated {
+			signature = comparableSignature(g)
+		}
+		diff, ok := groups[signature]
+		if !ok {
+			diff = &GroupDiff{
+				Signature: signature,
+			}
+			groups[signature] = diff
+			order = append(order, signature)
+		}
+		return diff
+	}
+
+	for _, g := range a.Threads {
+		diff := group(g)
+		diff.Before += goroutineCount(g)
+		if diff.Goroutine == nil {
+			diff.Goroutine = g
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, g := range b.Threads {
+		diff := group(g)
+		diff.After += goroutineCount(g)
+		if !seen[diff.Signature] {
+			diff.Goroutine = g
+			seen[diff.Signature] = true
+		}
+	}
+
+	result := &DumpDiff{}
+	for _, signature := range order {
+		diff := groups[signature]
+		switch {
+		case diff.Before == 0:
+			result.New = append(result.New, diff)
+		case diff.After == 0:
+			result.Vanished = append(result.Vanished, diff)
+		case diff.After > diff.Before:
+			result.Grown = append(result.Grown, diff)
+		case diff.After < diff.Before:
+			result.Shrunk = append(result.Shrunk, diff)
+		}
+	}
+
+	for _, list := range [][]*GroupDiff{result.New, result.Vanished, result.Grown, result.Shrunk} {
+		sortGroups(list)
+	}
+
+	return result
+}
+
+// isAggregated reports whether e is an aggregated goroutine profile.
+func isAggregated(e *Event) bool {
+	for _, g := range e.Threads {
+		if g.Count > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// comparableSignature returns the signature of g's stack as both profile
+// formats print it: without its creator or unexported runtime functions.
+func comparableSignature(g *Goroutine) string {
+	stack := &Goroutine{}
+	for _, f := range g.Frames {
+		if !hiddenRuntimeFrame(f) {
+			stack.Frames = append(stack.Frames, f)
+		}
+	}
+	return stack.Signature()
+}
+
+// hiddenRuntimeFrame reports whether the runtime leaves f out of goroutine
+// dumps, as it does for its unexported functions at the default traceback
+// level.
+func hiddenRuntimeFrame(f *Frame) bool {
+	if f.Native || f.Package != "runtime" {
+		return false
+	}
+
+	name, _ := utf8.DecodeRuneInString(f.Name())
+	return !unicode.IsUpper(name)
+}
+
+// goroutineCount returns how many goroutines g stands for.
+func goroutineCount(g *Goroutine) int {
+	if g.Count > 0 {
+		return g.Count
+	}
+	return 1
+}
+
+func sortGroups(groups []*GroupDiff) {
+	abs := func(n int) int {
+		if n < 0 {
+			return -n
+		}
+		return n
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return abs(groups[i].Delta()) > abs(groups[j].Delta())
+	})
+}
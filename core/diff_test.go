@@ -0,0 +1,120 @@
+package core_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/avos-io/panic-parse/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	before, err := core.ParseProfile(strings.NewReader("goroutine profile: total 9\n" +
+		"2 @ 0x1\n" +
+		"#\t0x1\tmain.worker+0x1\t/app/main.go:20\n" +
+		"\n" +
+		"3 @ 0x2\n" +
+		"#\t0x2\tmain.poller+0x1\t/app/main.go:30\n" +
+		"\n" +
+		"4 @ 0x3\n" +
+		"#\t0x3\tmain.cache+0x1\t/app/main.go:40\n"))
+	require.NoError(t, err)
+
+	after, err := core.ParseGoroutines(strings.NewReader(`goroutine 10 [select]:
+main.worker()
+	/app/main.go:20 +0x1
+
+goroutine 11 [select]:
+main.worker()
+	/app/main.go:20 +0x1
+
+goroutine 12 [select]:
+main.worker()
+	/app/main.go:20 +0x1
+
+goroutine 13 [select]:
+main.worker()
+	/app/main.go:20 +0x1
+
+goroutine 14 [chan send]:
+main.leak()
+	/app/main.go:50 +0x1
+
+goroutine 15 [select]:
+main.cache()
+	/app/main.go:40 +0x1
+`))
+	require.NoError(t, err)
+
+	diff := core.Diff(before, after)
+
+	require.Len(t, diff.New, 1)
+	assert.Equal(t, "main.leak /app/main.go:50\n", diff.New[0].Signature)
+	assert.Equal(t, "14", diff.New[0].Goroutine.ID)
+	assert.Equal(t, 1, diff.New[0].Delta())
+
+	require.Len(t, diff.Vanished, 1)
+	assert.Equal(t, "poller", diff.Vanished[0].Goroutine.Frames[0].Func)
+	assert.Equal(t, 3, diff.Vanished[0].Before)
+	assert.Equal(t, 0, diff.Vanished[0].After)
+
+	require.Len(t, diff.Grown, 1)
+	assert.Equal(t, "10", diff.Grown[0].Goroutine.ID)
+	assert.Equal(t, 2, diff.Grown[0].Before)
+	assert.Equal(t, 4, diff.Grown[0].After)
+
+	require.Len(t, diff.Shrunk, 1)
+	assert.Equal(t, -3, diff.Shrunk[0].Delta())
+
+	assert.Empty(t, core.Diff(after, after).Grown)
+}
+
+func TestDiffMixedFormats(t *testing.T) {
+	// The same process profiled with debug=1, which prints runtime
+	// internals but no creators, and then with debug=2
+	before, err := core.ParseProfile(strings.NewReader("goroutine profile: total 3\n" +
+		"2 @ 0x43a0b6 0x4066ab 0x6ad4b9 0x46a7a1\n" +
+		"#\t0x4066aa\truntime.chanrecv1+0x1a\t\t/usr/local/go/src/runtime/chan.go:442\n" +
+		"#\t0x6ad4b8\tmain.worker+0x98\t\t/app/main.go:20\n" +
+		"\n" +
+		"1 @ 0x43a0b6 0x43a0c7 0x46a7a1\n" +
+		"#\t0x43a0b5\truntime.gopark+0xd5\t\t/usr/local/go/src/runtime/proc.go:398\n" +
+		"#\t0x6ad5c8\tmain.main+0x28\t\t/app/main.go:12\n" +
+		"#\t0x43a0c6\truntime.main+0x2a6\t\t/usr/local/go/src/runtime/proc.go:267\n"))
+	require.NoError(t, err)
+
+	after, err := core.ParseProfile(strings.NewReader(`goroutine 1 [select]:
+main.main()
+	/app/main.go:12 +0x28
+
+goroutine 7 [chan receive]:
+main.worker()
+	/app/main.go:20 +0x98
+created by main.main in goroutine 1
+	/app/main.go:11 +0x1d
+
+goroutine 8 [chan receive]:
+main.worker()
+	/app/main.go:20 +0x98
+created by main.main in goroutine 1
+	/app/main.go:11 +0x1d
+
+goroutine 9 [chan receive]:
+main.worker()
+	/app/main.go:20 +0x98
+created by main.main in goroutine 1
+	/app/main.go:11 +0x1d
+`))
+	require.NoError(t, err)
+
+	diff := core.Diff(before, after)
+
+	assert.Empty(t, diff.New)
+	assert.Empty(t, diff.Vanished)
+	assert.Empty(t, diff.Shrunk)
+	require.Len(t, diff.Grown, 1)
+	assert.Equal(t, "main.worker /app/main.go:20\n", diff.Grown[0].Signature)
+	assert.Equal(t, 2, diff.Grown[0].Before)
+	assert.Equal(t, 3, diff.Grown[0].After)
+}
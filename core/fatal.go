@@ -0,0 +1,79 @@
+package core
+
+import "strings"
+
+// FatalKind is the kind of a fatal error. Unlike panics, fatal errors cannot
+// be recovered.
+type FatalKind string
+
+const (
+	FatalConcurrentMap  FatalKind = "concurrent map access"
+	FatalDeadlock       FatalKind = "deadlock"
+	FatalStackOverflow  FatalKind = "stack overflow"
+	FatalOutOfMemory    FatalKind = "out of memory"
+	FatalUnlockUnlocked FatalKind = "unlock of unlocked mutex"
+	FatalBadPointer     FatalKind = "bad pointer in heap"
+)
+
+// newFatal returns a panic for a fatal error line.
+func newFatal(msg string) *Panic {
+	return &Panic{
+		Type:      msg,
+		Fatal:     true,
+		FatalKind: classifyFatal(msg),
+	}
+}
+
+// classifyFatal recognizes the runtime's fatal error messages. It returns ""
+// for any other message.
+func classifyFatal(msg string) FatalKind {
+	switch {
+	// Maps report writes racing with writes, reads, iteration and clones
+	case strings.HasPrefix(msg, "concurrent map "):
+		return FatalConcurrentMap
+	case msg == "all goroutines are asleep - deadlock!":
+		return FatalDeadlock
+	case msg == "stack overflow":
+		return FatalStackOverflow
+	case msg == "out of memory", strings.HasPrefix(msg, "runtime: out of memory"):
+		return FatalOutOfMemory
+	case msg == "sync: unlock of unlocked mutex",
+		msg == "sync: Unlock of unlocked RWMutex",
+		msg == "sync: RUnlock of unlocked RWMutex":
+		return FatalUnlockUnlocked
+	case strings.HasPrefix(msg, "found bad pointer in Go heap"):
+		return FatalBadPointer
+	}
+	return ""
+}
+
+// markCrashed flags the goroutines that caused the event's crash.
+func markCrashed(e *Event) {
+	// Only fatal events crashed, and a runtime stack is the crashing
+	// context rather than the goroutine it ran for
+	if e.Level != "fatal" {
+		return
+	}
+
+	for _, g := range e.Threads {
+		if g.ID == e.Panic.ThreadId && e.SystemStack == nil {
+			g.Crashed = true
+		}
+
+		// Every goroutine caught in map code took part in the race
+		if e.Panic.FatalKind == FatalConcurrentMap && inMapCode(g) {
+			g.Crashed = true
+		}
+	}
+}
+
+// inMapCode reports whether g is running the runtime's map implementation.
+func inMapCode(g *Goroutine) bool {
+	for _, f := range g.Frames {
+		if f.Package == "runtime" && strings.HasPrefix(f.Func, "map") ||
+			f.Package == "internal/runtime/maps" {
+			return true
+		}
+	}
+	return false
+}
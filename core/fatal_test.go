@@ -0,0 +1,85 @@
+package core_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/avos-io/panic-parse/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const concurrentMapWrites = `fatal error: concurrent map writes
+
+goroutine 7 [running]:
+runtime.fatal({0x4b5c3a?, 0x0?})
+	/usr/local/go/src/runtime/panic.go:1007 +0x5c
+runtime.mapassign_fast64(0x4a3f20, 0xc00007a180, 0x1)
+	/usr/local/go/src/runtime/map_fast64.go:102 +0x2b6
+main.writer(0xc00007a180)
+	/app/main.go:12 +0x45
+created by main.main in goroutine 1
+	/app/main.go:20 +0x65
+
+goroutine 1 [sleep]:
+time.Sleep(0x3b9aca00)
+	/usr/local/go/src/runtime/time.go:195 +0x125
+main.main()
+	/app/main.go:22 +0x8a
+
+goroutine 8 [runnable]:
+runtime.mapassign_fast64(0x4a3f20, 0xc00007a180, 0x2)
+	/usr/local/go/src/runtime/map_fast64.go:93 +0x1f6
+main.writer(0xc00007a180)
+	/app/main.go:12 +0x45
+created by main.main in goroutine 1
+	/app/main.go:20 +0x65`
+
+func TestParseEventFatalKind(t *testing.T) {
+	cases := map[string]core.FatalKind{
+		"concurrent map writes":                 core.FatalConcurrentMap,
+		"concurrent map read and map write":     core.FatalConcurrentMap,
+		"all goroutines are asleep - deadlock!": core.FatalDeadlock,
+		"stack overflow":                        core.FatalStackOverflow,
+		"runtime: out of memory":                core.FatalOutOfMemory,
+		"sync: unlock of unlocked mutex":        core.FatalUnlockUnlocked,
+		"found bad pointer in Go heap (fresh)":  core.FatalBadPointer,
+		"something else":                        "",
+	}
+
+	for msg, kind := range cases {
+		event, err := core.ParseEvent(strings.NewReader("fatal error: " + msg + `
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d`))
+		require.NoError(t, err)
+
+		assert.True(t, event.Panic.Fatal, msg)
+		assert.Equal(t, kind, event.Panic.FatalKind, msg)
+	}
+
+	event, err := core.ParseEvent(strings.NewReader(multipleGoroutines))
+	require.NoError(t, err)
+	assert.False(t, event.Panic.Fatal)
+	assert.Empty(t, event.Panic.FatalKind)
+}
+
+func TestParseEventCrashed(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(concurrentMapWrites))
+	require.NoError(t, err)
+	require.Len(t, event.Threads, 3)
+
+	assert.True(t, event.Threads[0].Crashed)
+	assert.False(t, event.Threads[1].Crashed)
+	assert.True(t, event.Threads[2].Crashed)
+
+	event, err = core.ParseEvent(strings.NewReader(multipleGoroutines))
+	require.NoError(t, err)
+	assert.True(t, event.Threads[0].Crashed)
+	assert.False(t, event.Threads[1].Crashed)
+
+	event, err = core.ParseGoroutines(strings.NewReader(noPanic))
+	require.NoError(t, err)
+	assert.False(t, event.Threads[0].Crashed)
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Filter returns the goroutines in the event for which keep returns true, in
+// the order they were dumped.
+func (e *Event) Filter(keep func(*Goroutine) bool) []*Goroutine {
+	goroutines := []*Goroutine{}
+	for _, g := range e.Threads {
+		if keep(g) {
+			goroutines = append(goroutines, g)
+		}
+	}
+	return goroutines
+}
+
+// WaitingAtLeast returns a filter matching goroutines that had been blocked
+// for at least d.
+func WaitingAtLeast(d time.Duration) func(*Goroutine) bool {
+	return func(g *Goroutine) bool {
+		return g.WaitDuration >= d
+	}
+}
+
+// IsLockedToThread is a filter matching goroutines locked to their OS thread.
+func IsLockedToThread(g *Goroutine) bool {
+	return g.LockedToThread
+}
+
+// Signature identifies the goroutine's stack, so that goroutines blocked in
+// the same place share a signature whatever their IDs, states and arguments.
+func (g *Goroutine) Signature() string {
+	var b strings.Builder
+	for _, f := range g.Frames {
+		fmt.Fprintf(&b, "%s %s:%d\n", qualifiedName(f), f.File, f.Line)
+	}
+	if f := g.CreatedBy; f != nil {
+		fmt.Fprintf(&b, "created by %s %s:%d\n", qualifiedName(f), f.File, f.Line)
+	}
+	return b.String()
+}
+
+// qualifiedName returns the frame's function name with its package, if it
+// has one. Builtins such as panic have none.
+func qualifiedName(f *Frame) string {
+	switch {
+	case f.Native:
+		return f.RawFunc
+	case f.Package == "":
+		return f.Name()
+	}
+	return f.Package + "." + f.Name()
+}
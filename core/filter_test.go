@@ -0,0 +1,89 @@
+package core_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avos-io/panic-parse/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(`panic: oh no
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d
+
+goroutine 5 [chan receive, 3 minutes]:
+main.consumer()
+	/app/main.go:20 +0x2d
+
+goroutine 6 [syscall, 125 minutes, locked to thread]:
+main.poller()
+	/app/main.go:30 +0x3d
+
+goroutine 7 [select, locked to thread]:
+main.ui()
+	/app/main.go:40 +0x4d`))
+	require.NoError(t, err)
+	require.Len(t, event.Threads, 4)
+
+	assert.Equal(t, time.Duration(0), event.Threads[0].WaitDuration)
+	assert.Equal(t, 3*time.Minute, event.Threads[1].WaitDuration)
+	assert.Equal(t, "syscall", event.Threads[2].State)
+	assert.Equal(t, 125*time.Minute, event.Threads[2].WaitDuration)
+	assert.True(t, event.Threads[2].LockedToThread)
+	assert.False(t, event.Threads[1].LockedToThread)
+
+	ids := func(goroutines []*core.Goroutine) []string {
+		ids := []string{}
+		for _, g := range goroutines {
+			ids = append(ids, g.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"6"}, ids(event.Filter(core.WaitingAtLeast(time.Hour))))
+	assert.Equal(t, []string{"5", "6"}, ids(event.Filter(core.WaitingAtLeast(time.Minute))))
+	assert.Equal(t, []string{"6", "7"}, ids(event.Filter(core.IsLockedToThread)))
+}
+
+func TestSignature(t *testing.T) {
+	event, err := core.ParseGoroutines(strings.NewReader(`goroutine 5 [chan receive]:
+main.consumer(0xc000012345)
+	/app/main.go:20 +0x2d
+created by main.main in goroutine 1
+	/app/main.go:12 +0x5d
+
+goroutine 6 [select, 3 minutes]:
+main.consumer(0xc000067890)
+	/app/main.go:20 +0x2d
+created by main.main in goroutine 1
+	/app/main.go:12 +0x5d
+
+goroutine 7 [chan receive]:
+main.consumer(0xc000012345)
+	/app/main.go:22 +0x3d
+created by main.main in goroutine 1
+	/app/main.go:12 +0x5d`))
+	require.NoError(t, err)
+	require.Len(t, event.Threads, 3)
+
+	assert.Equal(t, "main.consumer /app/main.go:20\ncreated by main.main /app/main.go:12\n", event.Threads[0].Signature())
+	assert.Equal(t, event.Threads[0].Signature(), event.Threads[1].Signature())
+	assert.NotEqual(t, event.Threads[0].Signature(), event.Threads[2].Signature())
+
+	event, err = core.ParseEvent(strings.NewReader(`panic: oh no
+
+goroutine 1 [running]:
+panic({0x4a1d20?, 0x4c6b58?})
+	/usr/local/go/src/runtime/panic.go:770 +0x132
+main.main()
+	/app/main.go:10 +0x1d`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "panic /usr/local/go/src/runtime/panic.go:770\nmain.main /app/main.go:10\n", event.Threads[0].Signature())
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// nonGoFunction is printed for C frames the symbolizer found no name for.
+const nonGoFunction = "non-Go function"
+
+var (
+	nonGoPCRegexp      = regexp.MustCompile(`^non-Go function at pc=(0x[0-9a-f]+)$`)
+	nativeAddrRegexp   = regexp.MustCompile(`^(0x[0-9a-f]+) \?$`)
+	nativeOffsetRegexp = regexp.MustCompile(`^(\S+)\+(0x[0-9a-f]+)$`)
+	nativeSymbolRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.$@]*$`)
+	nativePCRegexp     = regexp.MustCompile(`^\s+pc=(0x[0-9a-f]+)$`)
+)
+
+// nativeFrame parses the function line of a C frame, as printed for cgo
+// tracebacks registered with runtime.SetCgoTraceback. The runtime prints
+// whatever the symbolizer returned, or "non-Go function", followed by a line
+// with the program counter. Without a symbolizer it prints a single "non-Go
+// function at pc=" line instead, which is complete by itself.
+//
+// A bare symbol could be any word, so such frames are only certain once the
+// program counter line is seen.
+func nativeFrame(line string) (frame *Frame, certain, complete bool) {
+	line = strings.TrimSpace(line)
+
+	frame = &Frame{
+		RawFunc: line,
+		Native:  true,
+	}
+
+	if line == nonGoFunction {
+		return frame, true, false
+	}
+
+	if matches := nonGoPCRegexp.FindStringSubmatch(line); matches != nil {
+		pc, err := strconv.ParseUint(matches[1], 0, 64)
+		if err != nil {
+			return nil, false, false
+		}
+		frame.PC = pc
+		return frame, true, true
+	}
+
+	if matches := nativeAddrRegexp.FindStringSubmatch(line); matches != nil {
+		pc, err := strconv.ParseUint(matches[1], 0, 64)
+		if err != nil {
+			return nil, false, false
+		}
+		frame.PC = pc
+		return frame, true, false
+	}
+
+	if matches := nativeOffsetRegexp.FindStringSubmatch(line); matches != nil {
+		offset, err := strconv.ParseInt(matches[2], 0, 64)
+		if err != nil {
+			return nil, false, false
+		}
+		frame.StackOffset = offset
+
+		// Symbolizers without symbols fall back to the shared object and
+		// the offset into it
+		if isModule(matches[1]) {
+			frame.Module = matches[1]
+		} else {
+			frame.Symbol = matches[1]
+		}
+		return frame, true, false
+	}
+
+	if nativeSymbolRegexp.MatchString(line) {
+		frame.Symbol = line
+		return frame, false, false
+	}
+
+	return nil, false, false
+}
+
+// isModule reports whether name looks like the path of a shared object or
+// executable rather than a symbol.
+func isModule(name string) bool {
+	return strings.Contains(name, "/") || strings.Contains(name, ".so") ||
+		strings.HasSuffix(name, ".dylib") || strings.HasSuffix(name, ".dll")
+}
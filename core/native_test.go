@@ -0,0 +1,131 @@
+package core_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/avos-io/panic-parse/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const cgoTraceback = `SIGSEGV: segmentation violation
+PC=0x7f5b6b5e5c4a m=0 sigcode=1 addr=0x0
+signal arrived during cgo execution
+
+goroutine 1 gp=0xc0000061c0 m=0 mp=0x5a4560 [syscall]:
+crash_in_c
+	/app/crash.c:5 pc=0x48061d
+libc.so.6+0x29d90
+	pc=0x7f5b6b429d90
+non-Go function
+	pc=0x7f5b6b429e40
+0x7f5b6b5e5c4a ?
+	pc=0x7f5b6b5e5c4a
+runtime.cgocall(0x4805e0, 0xc000067f40)
+	/usr/local/go/src/runtime/cgocall.go:157 +0x4b fp=0xc000067f18 sp=0xc000067ee0 pc=0x40460b
+main._Cfunc_crash()
+	_cgo_gotypes.go:39 +0x45 fp=0xc000067f40 sp=0xc000067f18 pc=0x4804c5
+main.main()
+	/app/main.go:12 +0x17`
+
+func TestParseEventNativeFrames(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(cgoTraceback))
+	require.NoError(t, err)
+	require.Empty(t, event.Diagnostics)
+
+	assert.True(t, event.Panic.InCgo)
+	require.Len(t, event.Threads, 1)
+
+	frames := event.Threads[0].Frames
+	require.Len(t, frames, 7)
+
+	assert.True(t, frames[0].Native)
+	assert.Equal(t, "crash_in_c", frames[0].Symbol)
+	assert.Equal(t, "crash_in_c", frames[0].Name())
+	assert.Equal(t, "/app/crash.c", frames[0].File)
+	assert.Equal(t, 5, frames[0].Line)
+	assert.Equal(t, uint64(0x48061d), frames[0].PC)
+
+	assert.True(t, frames[1].Native)
+	assert.Equal(t, "libc.so.6", frames[1].Module)
+	assert.Empty(t, frames[1].Symbol)
+	assert.Equal(t, int64(0x29d90), frames[1].StackOffset)
+	assert.Equal(t, uint64(0x7f5b6b429d90), frames[1].PC)
+
+	assert.True(t, frames[2].Native)
+	assert.Empty(t, frames[2].Symbol)
+	assert.Equal(t, uint64(0x7f5b6b429e40), frames[2].PC)
+
+	assert.True(t, frames[3].Native)
+	assert.Equal(t, uint64(0x7f5b6b5e5c4a), frames[3].PC)
+
+	assert.False(t, frames[4].Native)
+	assert.Equal(t, "cgocall", frames[4].Func)
+	assert.Equal(t, "main", frames[6].Func)
+}
+
+func TestParseEventNativeFrameWithoutPC(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(`panic: oh no
+
+goroutine 1 [running]:
+libfoo.so+0x1234
+main.main()
+	/app/main.go:10 +0x1d
+exit
+`))
+	require.NoError(t, err)
+	require.Empty(t, event.Diagnostics)
+
+	// The module frame is kept, but the bare word after the stack is not
+	frames := event.Threads[0].Frames
+	require.Len(t, frames, 2)
+	assert.Equal(t, "libfoo.so", frames[0].Module)
+	assert.Equal(t, "main", frames[1].Func)
+}
+
+// A cgo crash with a traceback function registered but no symbolizer, as
+// printed by the runtime
+const cgoTracebackNoSymbolizer = `SIGSEGV: segmentation violation
+PC=0x4805ad m=0 sigcode=1 addr=0x0
+signal arrived during cgo execution
+
+goroutine 1 gp=0xc000002380 m=0 mp=0x5a4560 [syscall]:
+non-Go function at pc=0x4805ad
+non-Go function at pc=0x7f2c4b629d90
+runtime.cgocall(0x480590, 0xc000067f38)
+	/usr/local/go/src/runtime/cgocall.go:167 +0x4b fp=0xc000067f10 sp=0xc000067ed8 pc=0x40484b
+main._Cfunc_crash()
+	_cgo_gotypes.go:39 +0x3f fp=0xc000067f38 sp=0xc000067f10 pc=0x4804bf
+main.main()
+	/app/main.go:14 +0x17 fp=0xc000067f50 sp=0xc000067f38 pc=0x4804f7
+
+rax    0x0
+rip    0x4805ad`
+
+func TestParseEventNonGoFunctionAtPC(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(cgoTracebackNoSymbolizer))
+	require.NoError(t, err)
+	require.Empty(t, event.Diagnostics)
+
+	assert.True(t, event.Panic.InCgo)
+	assert.Equal(t, uint64(0x4805ad), event.Panic.Registers["rip"])
+	require.Len(t, event.Threads, 1)
+
+	frames := event.Threads[0].Frames
+	require.Len(t, frames, 5)
+
+	assert.True(t, frames[0].Native)
+	assert.Empty(t, frames[0].Symbol)
+	assert.Equal(t, uint64(0x4805ad), frames[0].PC)
+
+	assert.True(t, frames[1].Native)
+	assert.Equal(t, uint64(0x7f2c4b629d90), frames[1].PC)
+
+	assert.False(t, frames[2].Native)
+	assert.Equal(t, "cgocall", frames[2].Func)
+	assert.Equal(t, uint64(0x40484b), frames[2].PC)
+	assert.Equal(t, "_Cfunc_crash", frames[3].Func)
+	assert.Equal(t, "main", frames[4].Func)
+	assert.Equal(t, "/app/main.go", frames[4].File)
+}
@@ -0,0 +1,938 @@
+// Package core parses Go panic logs and goroutine dumps into plain Go types.
+// It has no dependencies outside the standard library.
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	panicRegexp      = regexp.MustCompile(`^panic: (.*)$`)
+	chainedRegexp    = regexp.MustCompile(`^\s+panic: (.*)$`)
+	recoveredRegexp  = regexp.MustCompile(`^(.*) \[recovered(, repanicked)?\]$`)
+	fatalErrorRegexp = regexp.MustCompile(`^fatal error: (.*)$`)
+	signalRegexp     = regexp.MustCompile(`^\[signal\s([^:]+):\s(.*)\]$`)
+	sigHeaderRegexp  = regexp.MustCompile(`^(SIG[A-Z0-9]+): (.*)$`)
+	sigPCRegexp      = regexp.MustCompile(`^PC=(0x[0-9a-f]+) m=(\S+) sigcode=(\S+)(?: addr=(\S+))?`)
+	faultRegexp      = regexp.MustCompile(`^unexpected fault address (0x[0-9a-f]+)$`)
+	goroutineRegexp  = regexp.MustCompile(`^goroutine (\d+)(?: gp=(\S+) m=(\S+)(?: mp=(\S+))?)? \[([^,]+)(?:, (\d+) minutes)?(, locked to thread)?\]:$`)
+	registerRegexp   = regexp.MustCompile(`^([a-z][a-z0-9]*)\s+(0x[0-9a-f]+)$`)
+	ancestorRegexp   = regexp.MustCompile(`^\[originating from goroutine (\d+)\]:$`)
+	createdByRegexp  = regexp.MustCompile(`^created by (.+?)(?: in goroutine (\d+))?$`)
+	fileRegexp       = regexp.MustCompile(`^\s*(.+):(-?\d+)\s*(.*)$`)
+
+	runtimeStack       = []byte("runtime stack:")
+	relaySeparator     = []byte("-----")
+	cgoSignal          = []byte("signal arrived during cgo execution")
+	framesElided       = []byte("...additional frames elided...")
+	framesElidedRegexp = regexp.MustCompile(`^\.\.\.(\d+) frames elided\.\.\.$`)
+
+	stackUnavailable = []byte("goroutine running on other thread; stack unavailable")
+	exitStatusRegexp = regexp.MustCompile(`^exit status \d+$`)
+)
+
+// ErrNoPanic is returned by ParseEvent when the input contains no panic or
+// fatal error header.
+var ErrNoPanic = errors.New("no panic or fatal error found")
+
+// ErrNoGoroutines is returned by ParseGoroutines when the input contains
+// neither a header nor a goroutine.
+var ErrNoGoroutines = errors.New("no goroutines found")
+
+// HangDump is the Type of the synthetic panic given to goroutine dumps that
+// were not caused by a crash: SIGQUIT dumps and the output of runtime.Stack.
+const HangDump = "hang dump"
+
+type state int
+
+const (
+	stateInit state = iota
+	stateFatalError
+	statePanic
+	stateSignal
+	stateStackFunc
+	stateStackFile
+)
+
+// Event is a parsed panic: the panic itself plus every goroutine that was
+// dumped alongside it.
+type Event struct {
+	Panic   *Panic
+	Threads []*Goroutine
+	Level   string
+
+	// Chain lists every panic in the order printed when a deferred function
+	// panicked again while the first panic was being handled. Chain[0] is
+	// Panic, the original cause, and the last entry is the panic that
+	// finally crashed the program.
+	Chain []*Panic
+
+	// Preamble holds the "runtime: " lines printed with the trace, which
+	// usually come before the header, if any.
+	Preamble *Preamble
+
+	// SystemStack holds the frames printed under "runtime stack:" when the
+	// runtime crashed while running on the system stack rather than a
+	// goroutine's own, as for stack overflows and unexpected signals. It
+	// has no ID or State.
+	SystemStack *Goroutine
+
+	// Diagnostics lists the lines the parser skipped or only partially
+	// understood. Blank lines and known chatter such as "exit status 2"
+	// are skipped without one.
+	Diagnostics Diagnostics
+}
+
+type Panic struct {
+	Type        string
+	Description string
+	Synthetic   bool
+	Signal      string
+	SignalInfo  string
+	Code        string
+	Address     string
+	PC          string
+	ThreadId    string
+
+	// Registers holds the CPU registers the runtime dumps for fatal
+	// signals with GOTRACEBACK=crash, keyed by the runtime's names for
+	// them, such as rip on amd64 or pc on arm64.
+	Registers map[string]uint64
+
+	// Recovered is set when the panic was recovered before another panic
+	// was raised. Repanicked is set when it was recovered and then raised
+	// again with the same value.
+	Recovered  bool
+	Repanicked bool
+
+	// FaultAddress is the address of an unexpected fault, reported before
+	// a "fault" fatal error for bad accesses in unsafe or cgo code. InCgo
+	// is set when the signal arrived while running C code.
+	FaultAddress string
+	InCgo        bool
+
+	// Fatal is set for fatal errors, which cannot be recovered, and
+	// FatalKind classifies the ones the runtime is known to raise.
+	Fatal     bool
+	FatalKind FatalKind
+
+	// Runtime classifies panics raised by the runtime itself, such as
+	// bounds check failures. It is nil for any other panic.
+	Runtime *RuntimeError
+}
+
+type Goroutine struct {
+	ID           string
+	State        string
+	Frames       []*Frame
+	FramesElided bool
+
+	// ElidedAt is the index in Frames of the first frame printed after the
+	// elision marker, and ElidedCount how many frames were left out there.
+	// Since Go 1.21 the middle of long stacks is elided and the count is
+	// printed; older versions cut off the end of the stack, so ElidedAt is
+	// len(Frames) and ElidedCount is 0 as the count is unknown.
+	ElidedAt    int
+	ElidedCount int
+
+	// WaitDuration is how long the goroutine had been blocked, to the
+	// minute. The runtime only reports waits of a minute or more.
+	WaitDuration   time.Duration
+	LockedToThread bool
+
+	// GP is the address of the runtime's g for the goroutine, M the ID of
+	// the thread running it ("nil" if none) and MP that thread's address.
+	// They are only printed for runtime crashes and with
+	// GOTRACEBACK=system or higher.
+	GP string
+	M  string
+	MP string
+
+	// CreatedBy is the go statement that started the goroutine. It is not
+	// included in Frames. ParentID is the ID of the goroutine that ran it,
+	// which Go only prints since 1.21.
+	CreatedBy *Frame
+	ParentID  string
+
+	// Ancestors holds the stacks of the goroutines that created this one,
+	// nearest first, as printed with GODEBUG=tracebackancestors=N. The
+	// runtime only records where each ancestor was when it started the next
+	// goroutine, and ancestors have no State.
+	Ancestors []*Goroutine
+
+	// Count is how many goroutines share this stack in an aggregated
+	// goroutine profile, where goroutines have no ID or State, and Labels
+	// their pprof labels. Count is zero for individual goroutines.
+	Count  int
+	Labels map[string]string
+
+	// Crashed is set on the goroutines that caused a fatal event: the one
+	// that panicked and, for concurrent map access, every goroutine caught
+	// in map code.
+	Crashed bool
+}
+
+type Frame struct {
+	RawFunc  string
+	Package  string
+	Receiver string
+	Pointer  bool
+
+	// Func is the function or method name. Closures defined inside it are
+	// named by ClosurePath, such as ["func1", "2"] for Func.func1.2.
+	// TypeParams holds the type parameters of a generic function or
+	// receiver, which Go usually prints as "...".
+	Func        string
+	TypeParams  []string
+	ClosurePath []string
+
+	// MethodValue is set for the wrapper Go generates when a method is
+	// used as a value, printed with a -fm suffix.
+	MethodValue bool
+
+	File      string
+	Line      int
+	Arguments []*Arg
+
+	// StackOffset is the offset of the program counter from the start of
+	// the function. PC, SP and FP are the absolute program counter, stack
+	// pointer and frame pointer, which Go only prints for runtime crashes
+	// and with GOTRACEBACK=system or higher.
+	StackOffset int64
+	PC          uint64
+	SP          uint64
+	FP          uint64
+
+	// Inlined is set for calls the compiler inlined into their caller.
+	// They have no program counter of their own.
+	Inlined bool
+
+	// Native is set for C frames in cgo tracebacks. They have a Symbol
+	// rather than a Func when the symbolizer found one, or only a Module,
+	// the shared object, with StackOffset the offset into it. PC is set
+	// when the runtime printed it.
+	Native bool
+	Module string
+	Symbol string
+}
+
+// Name returns the function name without its package, such as
+// Server.serveStreams.func1, or the symbol of a native frame.
+func (f *Frame) Name() string {
+	if f.Native {
+		return f.Symbol
+	}
+
+	name := f.Func
+	if f.Receiver != "" {
+		name = f.Receiver + "." + name
+	}
+	if len(f.ClosurePath) > 0 {
+		name += "." + strings.Join(f.ClosurePath, ".")
+	}
+	return name
+}
+
+// ParseEvent parses a panic log into an Event. If the log contains several
+// panics only the first is returned; use a Scanner to read them all.
+//
+// If the log contains no panic or fatal error header a *ParseError wrapping
+// ErrNoPanic is returned. Lines that could only be partially parsed are
+// recorded in Event.Diagnostics rather than failing the parse.
+func ParseEvent(trace io.Reader) (*Event, error) {
+	return parseFirst(NewScanner(trace), ErrNoPanic)
+}
+
+// ParseGoroutines parses a goroutine dump into an Event. Unlike ParseEvent it
+// also accepts dumps with no header at all, such as the output of
+// runtime.Stack or debug.Stack, which are given a synthetic HangDump panic at
+// level "error". If the input contains no goroutines a *ParseError wrapping
+// ErrNoGoroutines is returned.
+func ParseGoroutines(trace io.Reader) (*Event, error) {
+	scanner := NewScanner(trace)
+	scanner.parser.dump = true
+
+	return parseFirst(scanner, ErrNoGoroutines)
+}
+
+func parseFirst(scanner *Scanner, notFound error) (*Event, error) {
+	event, err := scanner.Next()
+	if err == io.EOF {
+		return nil, &ParseError{
+			Line:  scanner.parser.lineNo,
+			State: scanner.parser.state.String(),
+			Err:   notFound,
+		}
+	}
+
+	return event, err
+}
+
+// parser is the line-by-line state machine shared by every entry point.
+type parser struct {
+	state  state
+	lineNo int
+
+	// dump makes a goroutine header with no event in progress start a
+	// hang dump
+	dump bool
+
+	event     *Event
+	goroutine *Goroutine
+	frame     *Frame
+
+	// stack is the goroutine or ancestor frames are being added to
+	stack *Goroutine
+
+	// preamble holds the "runtime: " lines seen since the last header, and
+	// fault the address of an unexpected fault reported before it
+	preamble []string
+	fault    string
+
+	// tentative is set while the native frame being parsed may not be a
+	// frame at all
+	tentative bool
+
+	// signal holds a line that looks like a signal header until the next
+	// line shows whether it is one, and signalLine its line number
+	signal     []byte
+	signalLine int
+
+	// crashing is set for events started by a panic or fatal error, after
+	// which the runtime may raise SIGABRT and dump the goroutines again.
+	// relay is set after the separator the runtime prints before relaying
+	// the signal to each other thread with GOTRACEBACK=crash. aborted is
+	// set once any such block has been folded into the event.
+	crashing bool
+	relay    bool
+	aborted  bool
+
+	// registers is set while register lines belong to the event's panic
+	// rather than to a later signal block
+	registers bool
+}
+
+// isHeader reports whether line starts a new event. Signal headers are
+// handled separately as they are only known to be headers once the line
+// after them is read.
+func isHeader(line []byte) bool {
+	return panicRegexp.Match(line) || fatalErrorRegexp.Match(line)
+}
+
+// isChatter reports whether line is output the runtime or go command prints
+// alongside a trace that carries nothing worth parsing.
+func isChatter(line []byte) bool {
+	return len(bytes.TrimSpace(line)) == 0 ||
+		bytes.Equal(bytes.TrimSpace(line), stackUnavailable) ||
+		exitStatusRegexp.Match(line)
+}
+
+// continues reports whether line, seen after a blank line, still belongs to
+// the trace of the event in progress.
+func (p *parser) continues(line []byte) bool {
+	return goroutineRegexp.Match(line) || registerRegexp.Match(line) ||
+		bytes.Equal(line, runtimeStack) || bytes.Equal(line, relaySeparator) ||
+		p.absorbs(line)
+}
+
+// absorbs reports whether line is the header of a signal block that belongs
+// to the event in progress rather than starting a new one: a block the
+// runtime relayed to another thread with GOTRACEBACK=crash, or the SIGABRT
+// block older runtimes print after a crash.
+func (p *parser) absorbs(line []byte) bool {
+	if p.event == nil || !sigHeaderRegexp.Match(line) {
+		return false
+	}
+
+	if p.relay {
+		return true
+	}
+	if !p.crashing || p.aborted {
+		return false
+	}
+
+	// The SIGABRT block only follows the goroutines of the crash
+	switch p.state {
+	case stateSignal, stateStackFunc, stateStackFile:
+		return true
+	}
+	return false
+}
+
+// fold continues the event in progress with the signal block whose header
+// was just read.
+func (p *parser) fold() {
+	p.relay = false
+	p.aborted = true
+	p.registers = p.event.Panic.Registers == nil
+	p.state = stateSignal
+}
+
+// feed processes a single line of input. If the line starts a new event, the
+// event in progress is finished and returned.
+func (p *parser) feed(line []byte) *Event {
+	p.lineNo++
+
+	// Lines that a blank line separates from the next header are not its
+	// preamble
+	if p.event == nil && len(bytes.TrimSpace(line)) == 0 {
+		p.preamble, p.fault = nil, ""
+	}
+
+	var done *Event
+
+	// A signal header is only a header when the signal's PC line follows it,
+	// as other output may start with a signal name too
+	if p.signal != nil {
+		header := p.signal
+		p.signal = nil
+
+		switch {
+		case !sigPCRegexp.Match(line):
+			p.diagnoseAt(p.signalLine, header, errUnrecognized)
+		case p.absorbs(header):
+			p.fold()
+		default:
+			done = p.next()
+			matches := sigHeaderRegexp.FindSubmatch(header)
+			p.start(newSignal(string(matches[1]), string(matches[2])))
+		}
+	}
+
+	if sigHeaderRegexp.Match(line) {
+		if p.relay && p.absorbs(line) {
+			p.fold()
+			return done
+		}
+
+		p.signal = append([]byte(nil), line...)
+		p.signalLine = p.lineNo
+		return done
+	}
+
+	if p.event != nil && bytes.Equal(line, relaySeparator) {
+		p.relay = true
+		return done
+	}
+	if len(bytes.TrimSpace(line)) > 0 {
+		p.relay = false
+	}
+
+	if p.event != nil && isHeader(line) {
+		done = p.next()
+	}
+
+restartSwitch:
+	switch p.state {
+	case stateInit:
+		matches := panicRegexp.FindSubmatch(line)
+		if matches == nil {
+			// Try fatal error handling instead
+			p.state = stateFatalError
+			goto restartSwitch
+		}
+
+		p.start(newPanic(string(matches[1])))
+
+	case stateFatalError:
+		matches := fatalErrorRegexp.FindSubmatch(line)
+		if matches == nil {
+			if p.dump && goroutineRegexp.Match(line) {
+				p.start(&Panic{
+					Type:      HangDump,
+					Synthetic: true,
+				})
+				p.state = stateSignal
+				goto restartSwitch
+			}
+
+			if bytes.HasPrefix(line, runtimePrefix) {
+				p.addPreamble(line)
+			}
+			if matches := faultRegexp.FindSubmatch(line); matches != nil {
+				p.fault = string(matches[1])
+			}
+
+			// No header matched, so look for one again on the next line
+			p.state = stateInit
+			break
+		}
+
+		p.start(newFatal(string(matches[1])))
+
+	case statePanic:
+		if matches := chainedRegexp.FindSubmatch(line); matches != nil {
+			p.event.Chain = append(p.event.Chain, newPanic(string(matches[1])))
+			break
+		}
+
+		if bytes.Equal(line, cgoSignal) {
+			p.event.Panic.InCgo = true
+			break
+		}
+
+		if matches := sigPCRegexp.FindSubmatch(line); matches != nil {
+			p.event.Panic.PC = string(matches[1])
+			p.event.Panic.Code = string(matches[3])
+			p.event.Panic.Address = string(matches[4])
+			break
+		}
+
+		matches := signalRegexp.FindSubmatch(line)
+		if matches == nil {
+			p.state = stateSignal
+			break
+		}
+
+		panic := p.event.Panic
+
+		sigInfo := strings.Split(panic.Type, ": ")
+
+		panic.Type = sigInfo[0]
+		if len(sigInfo) > 1 {
+			panic.Description = sigInfo[1]
+		}
+		panic.Synthetic = true
+
+		signal := string(matches[1])
+		extra := string(matches[2])
+
+		extraInfo := strings.Split(extra, " ")
+		panicInfo := []string{}
+
+		for _, info := range extraInfo {
+			if strings.Contains(info, "=") {
+				parts := strings.Split(info, "=")
+				switch parts[0] {
+				case "code":
+					panic.Code = parts[1]
+				case "addr":
+					panic.Address = parts[1]
+				case "pc":
+					panic.PC = parts[1]
+				}
+			} else {
+				panicInfo = append(panicInfo, info)
+			}
+		}
+
+		panic.Signal = signal
+		panic.SignalInfo = strings.Join(panicInfo, " ")
+
+	case stateSignal:
+		if bytes.HasPrefix(line, runtimePrefix) {
+			p.addPreamble(line)
+			break
+		}
+
+		if matches := faultRegexp.FindSubmatch(line); matches != nil {
+			p.fault = string(matches[1])
+			break
+		}
+
+		if bytes.Equal(line, cgoSignal) {
+			p.event.Panic.InCgo = true
+			break
+		}
+
+		if matches := registerRegexp.FindSubmatch(line); matches != nil {
+			if !p.registers {
+				break
+			}
+
+			value, err := strconv.ParseUint(string(matches[2]), 0, 64)
+			if err != nil {
+				p.diagnose(line, fmt.Errorf("failed to parse register: %w", err))
+				break
+			}
+
+			if p.event.Panic.Registers == nil {
+				p.event.Panic.Registers = map[string]uint64{}
+			}
+			p.event.Panic.Registers[string(matches[1])] = value
+			break
+		}
+
+		// The PC of a later signal block, such as the SIGABRT raised after a
+		// crash, is not the crash's own
+		if sigPCRegexp.Match(line) {
+			break
+		}
+
+		if bytes.Equal(line, runtimeStack) {
+			p.goroutine = &Goroutine{}
+			p.event.SystemStack = p.goroutine
+			p.stack = p.goroutine
+			p.state = stateStackFunc
+			break
+		}
+
+		matches := goroutineRegexp.FindSubmatch(line)
+		if matches == nil {
+			if !isChatter(line) {
+				p.diagnose(line, errUnrecognized)
+			}
+			break
+		}
+
+		id := string(matches[1])
+
+		// I think the first thread we see is the one that panicked
+		if p.event.Panic.ThreadId == "" {
+			p.event.Panic.ThreadId = id
+		}
+
+		// The SIGABRT block repeats goroutines that were already dumped.
+		// Relayed blocks print each thread's own goroutine 0, which differ
+		// by address.
+		repeated := false
+		if p.aborted {
+			for _, g := range p.event.Threads {
+				repeated = repeated || g.ID == id && g.GP == string(matches[2])
+			}
+		}
+
+		p.goroutine = &Goroutine{
+			ID:             id,
+			GP:             string(matches[2]),
+			M:              string(matches[3]),
+			MP:             string(matches[4]),
+			State:          string(matches[5]),
+			LockedToThread: len(matches[7]) > 0,
+		}
+
+		if len(matches[6]) > 0 {
+			minutes, err := strconv.Atoi(string(matches[6]))
+			if err != nil {
+				p.diagnose(line, fmt.Errorf("failed to parse wait duration: %w", err))
+			}
+			p.goroutine.WaitDuration = time.Duration(minutes) * time.Minute
+		}
+		if !repeated {
+			p.event.Threads = append(p.event.Threads, p.goroutine)
+		}
+		p.stack = p.goroutine
+
+		p.state = stateStackFunc
+
+	case stateStackFunc:
+		if bytes.HasPrefix(line, framesElided) {
+			p.stack.FramesElided = true
+			p.stack.ElidedAt = len(p.stack.Frames)
+			break
+		}
+
+		if matches := framesElidedRegexp.FindSubmatch(line); matches != nil {
+			count, err := strconv.Atoi(string(matches[1]))
+			if err != nil {
+				p.diagnose(line, fmt.Errorf("failed to parse elided frame count: %w", err))
+			}
+
+			p.stack.FramesElided = true
+			p.stack.ElidedAt = len(p.stack.Frames)
+			p.stack.ElidedCount = count
+			break
+		}
+
+		if matches := ancestorRegexp.FindSubmatch(line); matches != nil {
+			ancestor := &Goroutine{
+				ID: string(matches[1]),
+			}
+
+			// Each ancestor created the stack printed before it
+			if p.stack.ParentID == "" {
+				p.stack.ParentID = ancestor.ID
+			}
+
+			p.goroutine.Ancestors = append(p.goroutine.Ancestors, ancestor)
+			p.stack = ancestor
+			p.frame = nil
+			break
+		}
+
+		var name, args string
+		createdBy := createdByRegexp.FindSubmatch(line)
+		if createdBy != nil {
+			name = string(createdBy[1])
+		} else {
+			name, args, _ = splitFuncLine(string(line))
+		}
+
+		if !validSymbol(name) {
+			if frame, certain, complete := nativeFrame(string(line)); frame != nil {
+				p.frame = frame
+				if complete {
+					p.stack.Frames = append(p.stack.Frames, frame)
+					break
+				}
+
+				p.tentative = !certain
+				p.state = stateStackFile
+				break
+			}
+
+			p.state = stateSignal
+			goto restartSwitch
+		}
+
+		sym := decodeSymbol(name)
+		p.frame = &Frame{
+			RawFunc:     strings.TrimSpace(string(line)),
+			Package:     sym.Package,
+			Pointer:     sym.Pointer,
+			Receiver:    sym.Receiver,
+			Func:        sym.Func,
+			TypeParams:  sym.TypeParams,
+			ClosurePath: sym.ClosurePath,
+			MethodValue: sym.MethodValue,
+		}
+
+		arguments, err := parseArgs(args)
+		if err != nil {
+			p.diagnose(line, fmt.Errorf("failed to parse arguments: %w", err))
+		}
+		p.frame.Arguments = arguments
+
+		if createdBy != nil {
+			p.stack.CreatedBy = p.frame
+			p.stack.ParentID = string(createdBy[2])
+		} else {
+			p.stack.Frames = append(p.stack.Frames, p.frame)
+		}
+
+		p.state = stateStackFile
+
+	case stateStackFile:
+		if p.frame == nil {
+			p.diagnose(line, errFileWithoutFunc)
+			p.state = stateSignal
+			break
+		}
+
+		// Native frames are only added once their address line is seen
+		if p.frame.Native {
+			if matches := nativePCRegexp.FindSubmatch(line); matches != nil {
+				pc, err := strconv.ParseUint(string(matches[1]), 0, 64)
+				if err != nil {
+					p.diagnose(line, fmt.Errorf("failed to parse program counter: %w", err))
+				}
+				p.frame.PC = pc
+				p.stack.Frames = append(p.stack.Frames, p.frame)
+				p.state = stateStackFunc
+				break
+			}
+
+			if !bytes.Contains(line, []byte("pc=")) || !fileRegexp.Match(line) {
+				if !p.tentative {
+					p.stack.Frames = append(p.stack.Frames, p.frame)
+				}
+				p.frame = nil
+				p.state = stateStackFunc
+				goto restartSwitch
+			}
+
+			p.stack.Frames = append(p.stack.Frames, p.frame)
+		}
+
+		matches := fileRegexp.FindSubmatch(line)
+		if matches == nil {
+			p.state = stateSignal
+			goto restartSwitch
+		}
+
+		lineNum, err := strconv.Atoi(string(matches[2]))
+		if err != nil {
+			p.diagnose(line, fmt.Errorf("failed to parse line number: %w", err))
+			lineNum = 0
+		}
+
+		p.frame.File = string(matches[1])
+		p.frame.Line = lineNum
+
+		hasOffset := false
+		for _, field := range strings.Fields(string(matches[3])) {
+			var what string
+			var err error
+			switch {
+			case strings.HasPrefix(field, "+"):
+				what = "stack offset"
+				p.frame.StackOffset, err = strconv.ParseInt(field, 0, 64)
+				hasOffset = true
+			case strings.HasPrefix(field, "fp="):
+				what = "frame pointer"
+				p.frame.FP, err = strconv.ParseUint(field[3:], 0, 64)
+			case strings.HasPrefix(field, "sp="):
+				what = "stack pointer"
+				p.frame.SP, err = strconv.ParseUint(field[3:], 0, 64)
+			case strings.HasPrefix(field, "pc="):
+				what = "program counter"
+				p.frame.PC, err = strconv.ParseUint(field[3:], 0, 64)
+			default:
+				what = "frame field"
+				err = errUnknownField
+			}
+			if err != nil {
+				p.diagnose(line, fmt.Errorf("failed to parse %s: %w", what, err))
+			}
+		}
+
+		// The runtime prints neither an offset nor the frame registers for
+		// inlined calls, and elides their arguments
+		p.frame.Inlined = !hasOffset && p.frame.PC == 0 &&
+			len(p.frame.Arguments) == 1 && p.frame.Arguments[0].Elided
+
+		p.state = stateStackFunc
+	}
+
+	return done
+}
+
+// newPanic returns a panic for a panic line, stripping any recovered marker.
+func newPanic(msg string) *Panic {
+	matches := recoveredRegexp.FindStringSubmatch(msg)
+	if matches == nil {
+		return &Panic{
+			Type: msg,
+		}
+	}
+
+	return &Panic{
+		Type:       matches[1],
+		Recovered:  true,
+		Repanicked: matches[2] != "",
+	}
+}
+
+// newSignal returns a panic for a signal header such as "SIGQUIT: quit",
+// which the runtime prints when a signal kills the process outside of a
+// panic. SIGQUIT asks for a goroutine dump, so it is reported as a hang dump.
+func newSignal(signal, info string) *Panic {
+	panic := &Panic{
+		Type:        signal,
+		Description: info,
+		Synthetic:   true,
+		Signal:      signal,
+		SignalInfo:  info,
+	}
+
+	if signal == "SIGQUIT" {
+		panic.Type = HangDump
+		panic.Description = signal + ": " + info
+	}
+
+	return panic
+}
+
+// addPreamble records a "runtime: " line for the next event, keeping only
+// the last maxPreamble lines.
+func (p *parser) addPreamble(line []byte) {
+	p.preamble = append(p.preamble, string(line))
+	if len(p.preamble) > maxPreamble {
+		p.preamble = p.preamble[len(p.preamble)-maxPreamble:]
+	}
+}
+
+// pending reports whether lines for an event that has not started yet are
+// being held.
+func (p *parser) pending() bool {
+	return len(p.preamble) > 0 || p.fault != "" || p.signal != nil
+}
+
+// next finishes the event in progress because a header starts a new one.
+func (p *parser) next() *Event {
+	// Preamble lines read since the last event belong to the new one
+	preamble, fault := p.preamble, p.fault
+	p.preamble, p.fault = nil, ""
+	done := p.finish()
+	p.preamble, p.fault = preamble, fault
+
+	return done
+}
+
+// start begins a new event for the given panic header.
+func (p *parser) start(panic *Panic) {
+	p.event = &Event{
+		Panic: panic,
+		Chain: []*Panic{panic},
+		Level: "fatal",
+	}
+	if len(p.preamble) > 0 {
+		p.event.Preamble = newPreamble(p.preamble)
+		p.preamble = nil
+	}
+	panic.FaultAddress = p.fault
+	p.fault = ""
+	if panic.Type == HangDump {
+		p.event.Level = "error"
+	}
+	p.crashing = !panic.Synthetic
+	p.registers = true
+	p.state = statePanic
+}
+
+// finish returns the event in progress, if any, and resets the parser to
+// look for the next header.
+func (p *parser) finish() *Event {
+	event := p.event
+	if event != nil {
+		// Nothing followed the signal header to make it one
+		if p.signal != nil {
+			p.diagnoseAt(p.signalLine, p.signal, errUnrecognized)
+		}
+
+		for _, panic := range event.Chain {
+			panic.Runtime = Classify(panic.message())
+		}
+		markCrashed(event)
+
+		// Lines that no header followed are the runtime's last words on
+		// this event
+		if len(p.preamble) > 0 {
+			lines := p.preamble
+			if event.Preamble != nil {
+				lines = append(event.Preamble.Lines, lines...)
+			}
+			event.Preamble = newPreamble(lines)
+		}
+	}
+
+	p.state = stateInit
+	p.event = nil
+	p.goroutine = nil
+	p.stack = nil
+	p.frame = nil
+	p.preamble = nil
+	p.fault = ""
+	p.signal = nil
+	p.crashing = false
+	p.relay = false
+	p.aborted = false
+
+	return event
+}
+
+// diagnose records an anomaly on the event in progress.
+func (p *parser) diagnose(line []byte, err error) {
+	p.diagnoseAt(p.lineNo, line, err)
+}
+
+// diagnoseAt records an anomaly on an earlier line.
+func (p *parser) diagnoseAt(lineNo int, line []byte, err error) {
+	if p.event == nil {
+		return
+	}
+
+	p.event.Diagnostics = append(p.event.Diagnostics, &ParseError{
+		Line:  lineNo,
+		Raw:   string(line),
+		State: p.state.String(),
+		Err:   err,
+	})
+}
@@ -0,0 +1,614 @@
+package core_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avos-io/panic-parse/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEvent(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(multipleGoroutines))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Something went wrong in packageA.foo()", event.Panic.Type)
+	assert.Equal(t, "1", event.Panic.ThreadId)
+	require.Len(t, event.Threads, 2)
+	assert.Equal(t, "running", event.Threads[1].State)
+	require.Len(t, event.Threads[0].Frames, 3)
+	assert.Equal(t, "github.com/user/packageA", event.Threads[0].Frames[0].Package)
+	assert.Equal(t, "foo", event.Threads[0].Frames[0].Func)
+	assert.Equal(t, "/path/to/packageA/foo.go", event.Threads[0].Frames[0].File)
+	assert.Equal(t, 10, event.Threads[0].Frames[0].Line)
+}
+
+func TestParseEventNoPanic(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(noPanic))
+	assert.ErrorIs(t, err, core.ErrNoPanic)
+	assert.Nil(t, event)
+
+	var parseErr *core.ParseError
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, 3, parseErr.Line)
+}
+
+func TestParseEventDiagnostics(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(`panic: oh no
+
+goroutine 1 [running]:
+main.main()
+	/tmp/main.go:23 +0xZZ`))
+	require.NoError(t, err)
+
+	require.Len(t, event.Diagnostics, 1)
+	d := event.Diagnostics[0]
+	assert.Equal(t, 5, d.Line)
+	assert.Equal(t, "\t/tmp/main.go:23 +0xZZ", d.Raw)
+	assert.Equal(t, "stack file", d.State)
+	assert.Contains(t, d.Error(), "failed to parse stack offset")
+
+	frame := event.Threads[0].Frames[0]
+	assert.Equal(t, 23, frame.Line)
+	assert.Equal(t, int64(0), frame.StackOffset)
+}
+
+func TestParseEventUnrecognizedLine(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(`panic: oh no
+
+goroutine 1 [running]:
+main.main()
+	/tmp/main.go:23 +0x1d
+2024/01/02 15:04:05 interleaved log line
+
+goroutine 5 [select]:
+main.worker()
+	/tmp/main.go:40 +0x2d
+exit status 2
+`))
+	require.NoError(t, err)
+
+	require.Len(t, event.Threads, 2)
+	require.Len(t, event.Diagnostics, 1)
+	d := event.Diagnostics[0]
+	assert.Equal(t, 6, d.Line)
+	assert.Equal(t, "2024/01/02 15:04:05 interleaved log line", d.Raw)
+	assert.Equal(t, "signal", d.State)
+	assert.Contains(t, d.Error(), "unrecognized line")
+}
+
+func TestParseEventChainedPanics(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(`panic: first [recovered]
+	panic: second [recovered, repanicked]
+	panic: third
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d`))
+	require.NoError(t, err)
+
+	require.Len(t, event.Chain, 3)
+	assert.Same(t, event.Panic, event.Chain[0])
+
+	assert.Equal(t, "first", event.Chain[0].Type)
+	assert.True(t, event.Chain[0].Recovered)
+	assert.False(t, event.Chain[0].Repanicked)
+
+	assert.Equal(t, "second", event.Chain[1].Type)
+	assert.True(t, event.Chain[1].Recovered)
+	assert.True(t, event.Chain[1].Repanicked)
+
+	assert.Equal(t, "third", event.Chain[2].Type)
+	assert.False(t, event.Chain[2].Recovered)
+
+	assert.Equal(t, "1", event.Panic.ThreadId)
+	assert.Len(t, event.Threads, 1)
+}
+
+func TestParseEventFrameAddresses(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(`fatal error: oh no
+
+goroutine 1 [running]:
+runtime.throw({0x112c00, 0x1040a038})
+	/usr/local/go/src/runtime/panic.go:1116 +0x72 fp=0x7fffbf9f7f18 sp=0x7fffbf9f7f00 pc=0x40c2e2
+runtime.systemstack_switch()
+	/usr/local/go/src/runtime/asm_amd64.s:351 fp=0xc0000b7f58 sp=0xc0000b7f50 pc=0x45a1a0
+main.inlined(...)
+	/app/main.go:20
+main.main()
+	/app/main.go:30 +0x2d`))
+	require.NoError(t, err)
+	require.Empty(t, event.Diagnostics)
+
+	frames := event.Threads[0].Frames
+	require.Len(t, frames, 4)
+
+	assert.Equal(t, int64(0x72), frames[0].StackOffset)
+	assert.Equal(t, uint64(0x7fffbf9f7f18), frames[0].FP)
+	assert.Equal(t, uint64(0x7fffbf9f7f00), frames[0].SP)
+	assert.Equal(t, uint64(0x40c2e2), frames[0].PC)
+	assert.False(t, frames[0].Inlined)
+
+	assert.Equal(t, int64(0), frames[1].StackOffset)
+	assert.Equal(t, uint64(0x45a1a0), frames[1].PC)
+	assert.False(t, frames[1].Inlined)
+
+	assert.True(t, frames[2].Inlined)
+	assert.Equal(t, uint64(0), frames[2].PC)
+
+	assert.Equal(t, int64(0x2d), frames[3].StackOffset)
+	assert.False(t, frames[3].Inlined)
+}
+
+func TestParseEventNegativeLine(t *testing.T) {
+	// The runtime printed a negative line for this asynchronously preempted
+	// loop
+	event, err := core.ParseEvent(strings.NewReader(`fatal error: oh no
+
+goroutine 5 gp=0x30f4001b1a40 m=nil [runnable]:
+main.spin()
+	/tmp/relay/cgo.go:-2 +0x1 fp=0x30f4001e47e0 sp=0x30f4001e47d8 pc=0x481741
+runtime.goexit({})
+	/usr/local/go/src/runtime/asm_amd64.s:1264 +0x1 fp=0x30f4001e47e8 sp=0x30f4001e47e0 pc=0x47d581`))
+	require.NoError(t, err)
+	require.Empty(t, event.Diagnostics)
+
+	frames := event.Threads[0].Frames
+	require.Len(t, frames, 2)
+	assert.Equal(t, "/tmp/relay/cgo.go", frames[0].File)
+	assert.Equal(t, -2, frames[0].Line)
+	assert.Equal(t, uint64(0x481741), frames[0].PC)
+}
+
+func TestParseEventFramesElided(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(`panic: oh no
+
+goroutine 1 [running]:
+main.recurse(0x0)
+	/app/main.go:10 +0x1d
+main.recurse(0x1)
+	/app/main.go:12 +0x2d
+...12 frames elided...
+main.recurse(0xe)
+	/app/main.go:12 +0x2d
+main.main()
+	/app/main.go:20 +0x3d
+
+goroutine 2 [running]:
+main.recurse(0x0)
+	/app/main.go:10 +0x1d
+main.recurse(0x1)
+	/app/main.go:12 +0x2d
+...additional frames elided...`))
+	require.NoError(t, err)
+	require.Empty(t, event.Diagnostics)
+
+	g := event.Threads[0]
+	assert.Len(t, g.Frames, 4)
+	assert.True(t, g.FramesElided)
+	assert.Equal(t, 2, g.ElidedAt)
+	assert.Equal(t, 12, g.ElidedCount)
+
+	g = event.Threads[1]
+	assert.Len(t, g.Frames, 2)
+	assert.True(t, g.FramesElided)
+	assert.Equal(t, 2, g.ElidedAt)
+	assert.Equal(t, 0, g.ElidedCount)
+}
+
+func TestParseEventSystemGoroutineHeaders(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(`panic: oh no
+
+goroutine 1 gp=0xc000002380 m=0 mp=0x9a4bc0 [running]:
+main.main()
+	/app/main.go:10 +0x1d fp=0xc000063f50 sp=0xc000063f30 pc=0x49a0bd
+
+goroutine 2 gp=0xc000002e00 m=nil [force gc (idle), 5 minutes]:
+runtime.gopark(0x0?, 0x0?, 0x0?, 0x0?, 0x0?)
+	/usr/local/go/src/runtime/proc.go:424 +0xce fp=0xc00004cfa8 sp=0xc00004cf88 pc=0x46e0ce
+
+goroutine 3 [select, locked to thread]:
+main.ui()
+	/app/main.go:40 +0x4d`))
+	require.NoError(t, err)
+	require.Len(t, event.Threads, 3)
+
+	g := event.Threads[0]
+	assert.Equal(t, "1", g.ID)
+	assert.Equal(t, "running", g.State)
+	assert.Equal(t, "0xc000002380", g.GP)
+	assert.Equal(t, "0", g.M)
+	assert.Equal(t, "0x9a4bc0", g.MP)
+	assert.Len(t, g.Frames, 1)
+
+	g = event.Threads[1]
+	assert.Equal(t, "force gc (idle)", g.State)
+	assert.Equal(t, "0xc000002e00", g.GP)
+	assert.Equal(t, "nil", g.M)
+	assert.Equal(t, "", g.MP)
+	assert.Equal(t, 5*time.Minute, g.WaitDuration)
+
+	g = event.Threads[2]
+	assert.Equal(t, "", g.GP)
+	assert.Equal(t, "", g.M)
+	assert.True(t, g.LockedToThread)
+}
+
+func TestParseEventRegisters(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(`panic: oh no
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d
+
+goroutine 2 [select]:
+main.worker()
+	/app/main.go:20 +0x2d
+
+r0      0x0
+r29     0x4000055e98
+lr      0x72c34
+sp      0x4000055e90
+pc      0x72c38
+fault   0x0`))
+	require.NoError(t, err)
+	require.Empty(t, event.Diagnostics)
+
+	assert.Len(t, event.Threads, 2)
+	assert.Len(t, event.Threads[1].Frames, 1)
+	assert.Equal(t, map[string]uint64{
+		"r0":    0x0,
+		"r29":   0x4000055e98,
+		"lr":    0x72c34,
+		"sp":    0x4000055e90,
+		"pc":    0x72c38,
+		"fault": 0x0,
+	}, event.Panic.Registers)
+}
+
+const stackOverflow = `runtime: goroutine stack exceeds 1000000000-byte limit
+runtime: sp=0xc020160398 stack=[0xc020160000, 0xc040160000]
+fatal error: stack overflow
+
+runtime stack:
+runtime.throw({0x4a4a5e?, 0x52e6c0?})
+	/usr/local/go/src/runtime/panic.go:1047 +0x5d fp=0x7ffd1e2b7e70 sp=0x7ffd1e2b7e40 pc=0x4340dd
+runtime.newstack()
+	/usr/local/go/src/runtime/stack.go:1103 +0x5cc fp=0x7ffd1e2b8028 sp=0x7ffd1e2b7e70 pc=0x44c70c
+runtime.morestack()
+	/usr/local/go/src/runtime/asm_amd64.s:570 +0x8b fp=0x7ffd1e2b8030 sp=0x7ffd1e2b8028 pc=0x45f0ab
+
+goroutine 1 [running]:
+main.recurse(0x0?)
+	/app/main.go:5 +0x3c fp=0xc0201603a8 sp=0xc0201603a0 pc=0x457cfc
+main.main()
+	/app/main.go:9 +0x17 fp=0xc040160000 sp=0xc04015ffe8 pc=0x457d37
+`
+
+func TestParseEventSystemStack(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(stackOverflow))
+	require.NoError(t, err)
+	require.Empty(t, event.Diagnostics)
+
+	assert.Equal(t, "stack overflow", event.Panic.Type)
+	assert.Equal(t, "1", event.Panic.ThreadId)
+
+	require.NotNil(t, event.SystemStack)
+	assert.Empty(t, event.SystemStack.ID)
+	require.Len(t, event.SystemStack.Frames, 3)
+	assert.Equal(t, "throw", event.SystemStack.Frames[0].Func)
+	assert.Equal(t, "morestack", event.SystemStack.Frames[2].Func)
+	assert.Equal(t, uint64(0x45f0ab), event.SystemStack.Frames[2].PC)
+
+	require.Len(t, event.Threads, 1)
+	assert.Equal(t, "1", event.Threads[0].ID)
+	assert.Len(t, event.Threads[0].Frames, 2)
+}
+
+func TestParseEventSIGQUIT(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(`SIGQUIT: quit
+PC=0x46e0e1 m=0 sigcode=0
+
+goroutine 0 gp=0x5a3e20 m=0 mp=0x5a4560 [idle]:
+runtime.futex(0x5a46a0, 0x80, 0x0, 0x0, 0x0, 0x0)
+	/usr/local/go/src/runtime/sys_linux_amd64.s:557 +0x21 fp=0x7ffd9b7e0f38 sp=0x7ffd9b7e0f30 pc=0x46e0e1
+
+goroutine 1 gp=0xc000006380 m=nil [select, 3 minutes]:
+main.main()
+	/app/main.go:10 +0x1d`))
+	require.NoError(t, err)
+	require.Empty(t, event.Diagnostics)
+
+	assert.Equal(t, core.HangDump, event.Panic.Type)
+	assert.Equal(t, "SIGQUIT: quit", event.Panic.Description)
+	assert.Equal(t, "SIGQUIT", event.Panic.Signal)
+	assert.Equal(t, "0x46e0e1", event.Panic.PC)
+	assert.Equal(t, "0", event.Panic.Code)
+	assert.Equal(t, "error", event.Level)
+	require.Len(t, event.Threads, 2)
+	assert.Equal(t, 3*time.Minute, event.Threads[1].WaitDuration)
+}
+
+const panicWithAbort = `panic: oh no
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d
+
+SIGABRT: abort
+PC=0x45e1b1 m=0 sigcode=0
+
+goroutine 0 [idle]:
+runtime.futex(0x5a46a0, 0x80, 0x0, 0x0, 0x0, 0x0)
+	/usr/local/go/src/runtime/sys_linux_amd64.s:557 +0x21
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d
+
+rax    0x0
+rip    0x45e1b1
+`
+
+func TestParseEventAbortAfterPanic(t *testing.T) {
+	scanner := core.NewScanner(strings.NewReader(panicWithAbort + `
+SIGQUIT: quit
+PC=0x46e0e1 m=0 sigcode=0
+
+goroutine 1 [select]:
+main.main()
+	/app/main.go:12 +0x1d
+`))
+
+	event, err := scanner.Next()
+	require.NoError(t, err)
+	require.Empty(t, event.Diagnostics)
+
+	// The SIGABRT block is merged into the panic, without repeating
+	// goroutine 1
+	assert.Equal(t, "oh no", event.Panic.Type)
+	assert.Empty(t, event.Panic.Signal)
+	assert.Empty(t, event.Panic.PC)
+	assert.Equal(t, "1", event.Panic.ThreadId)
+	require.Len(t, event.Threads, 2)
+	assert.Equal(t, "1", event.Threads[0].ID)
+	assert.Equal(t, "0", event.Threads[1].ID)
+	assert.Equal(t, uint64(0x45e1b1), event.Panic.Registers["rip"])
+
+	// Only crashes are followed by an abort, so other signals still start
+	// a new event
+	event, err = scanner.Next()
+	require.NoError(t, err)
+	assert.Equal(t, core.HangDump, event.Panic.Type)
+}
+
+// relayedBlocks is what the runtime prints after a crash with
+// GOTRACEBACK=crash as it relays the signal to each other thread, trimmed to
+// a few frames and registers.
+const relayedBlocks = `
+-----
+
+SIGQUIT: quit
+PC=0x47ead7 m=2 sigcode=0
+
+goroutine 0 gp=0x30f4001b0b40 m=2 mp=0x30f4001e8808 [idle]:
+runtime.usleep(0x2710)
+	/usr/local/go/src/runtime/sys_linux_amd64.s:135 +0x37 fp=0x7f242ce5eda8 sp=0x7f242ce5ed88 pc=0x47ead7
+runtime.sysmon()
+	/usr/local/go/src/runtime/proc.go:6557 +0xb2 fp=0x7f242ce5ee28 sp=0x7f242ce5eda8 pc=0x453672
+rax    0xfffffffffffffffc
+rip    0x47ead7
+rflags 0x202
+
+-----
+
+SIGQUIT: quit
+PC=0x47f0a1 m=0 sigcode=0
+
+goroutine 0 gp=0x5367e0 m=0 mp=0x5375a0 [idle]:
+runtime.futex(0x5376f8, 0x80, 0x0, 0x0, 0x0, 0x0)
+	/usr/local/go/src/runtime/sys_linux_amd64.s:575 +0x21 fp=0x7fff02cfbd88 sp=0x7fff02cfbd80 pc=0x47f0a1
+runtime.futexsleep(0x5375a0?, 0x2cfbe00?, 0x454fc8?)
+	/usr/local/go/src/runtime/os_linux.go:73 +0x30 fp=0x7fff02cfbdd8 sp=0x7fff02cfbd88 pc=0x4417f0
+rax    0xca
+rip    0x47f0a1
+rflags 0x286
+`
+
+const cgoCrash = `SIGSEGV: segmentation violation
+PC=0x481820 m=3 sigcode=1 addr=0x0
+signal arrived during cgo execution
+
+goroutine 1 gp=0x30f4001b01e0 m=3 mp=0x30f4001e9008 [syscall]:
+runtime.cgocall(0x481820, 0x30f4001f7e98)
+	/usr/local/go/src/runtime/cgocall.go:167 +0x4b fp=0x30f4001f7e70 sp=0x30f4001f7e38 pc=0x47700b
+main._Cfunc_crash()
+	_cgo_gotypes.go:46 +0x3a fp=0x30f4001f7e98 sp=0x30f4001f7e70 pc=0x48171a
+main.main()
+	/tmp/relay/cgo.go:24 +0x4a fp=0x30f4001f7eb8 sp=0x30f4001f7e98 pc=0x4817aa
+
+rax    0x481820
+rip    0x481820
+rflags 0x10216
+`
+
+const quitDump = `SIGQUIT: quit
+PC=0x47d757 m=1 sigcode=0
+
+goroutine 0 gp=0x313908fde5a0 m=1 mp=0x313909014008 [idle]:
+runtime.usleep(0x2710)
+	/usr/local/go/src/runtime/sys_linux_amd64.s:135 +0x37 fp=0x313909003f20 sp=0x313909003f00 pc=0x47d757
+
+goroutine 1 gp=0x313908fde1e0 m=nil [runnable]:
+time.Sleep(0x3b9aca00?)
+	/usr/local/go/src/runtime/time.go:335 +0x17b fp=0x313909028e70 sp=0x313909028e68 pc=0x4795bb
+main.main()
+	/tmp/relay/main.go:22 +0x93 fp=0x313909028eb8 sp=0x313909028e70 pc=0x4832b3
+
+rax    0xfffffffffffffffc
+rip    0x47d757
+rflags 0x202
+`
+
+func TestParseEventRelayedSignals(t *testing.T) {
+	scanner := core.NewScanner(strings.NewReader(cgoCrash + relayedBlocks))
+
+	event, err := scanner.Next()
+	require.NoError(t, err)
+	require.Empty(t, event.Diagnostics)
+
+	assert.Equal(t, "SIGSEGV", event.Panic.Signal)
+	assert.Equal(t, "0x481820", event.Panic.PC)
+	assert.True(t, event.Panic.InCgo)
+	assert.Equal(t, uint64(0x481820), event.Panic.Registers["rip"])
+
+	// Each relayed block adds the stack of another thread
+	require.Len(t, event.Threads, 3)
+	assert.Equal(t, "1", event.Threads[0].ID)
+	assert.Equal(t, "2", event.Threads[1].M)
+	assert.Equal(t, "0", event.Threads[2].M)
+	assert.Len(t, event.Threads[2].Frames, 2)
+
+	_, err = scanner.Next()
+	assert.ErrorIs(t, err, io.EOF)
+
+	// The same goes for hang dumps, which aren't crashes
+	scanner = core.NewScanner(strings.NewReader(quitDump + relayedBlocks))
+
+	event, err = scanner.Next()
+	require.NoError(t, err)
+	require.Empty(t, event.Diagnostics)
+	assert.Equal(t, core.HangDump, event.Panic.Type)
+	assert.Equal(t, uint64(0x47d757), event.Panic.Registers["rip"])
+	assert.Len(t, event.Threads, 4)
+
+	_, err = scanner.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestParseEventSignalLogLine(t *testing.T) {
+	scanner := core.NewScanner(strings.NewReader(`SIGHUP: reloading config
+panic: oh no
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d
+
+SIGTERM: shutting down
+`))
+
+	// Without a PC line neither is a signal header
+	event, err := scanner.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "oh no", event.Panic.Type)
+	require.Len(t, event.Diagnostics, 1)
+	assert.Equal(t, "SIGTERM: shutting down", event.Diagnostics[0].Raw)
+	assert.Equal(t, 8, event.Diagnostics[0].Line)
+
+	_, err = scanner.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestParseGoroutines(t *testing.T) {
+	_, err := core.ParseEvent(strings.NewReader(noPanic))
+	require.ErrorIs(t, err, core.ErrNoPanic)
+
+	event, err := core.ParseGoroutines(strings.NewReader(noPanic))
+	require.NoError(t, err)
+
+	assert.Equal(t, core.HangDump, event.Panic.Type)
+	assert.True(t, event.Panic.Synthetic)
+	assert.Equal(t, "1", event.Panic.ThreadId)
+	assert.Equal(t, "error", event.Level)
+	require.Len(t, event.Threads, 1)
+	assert.Len(t, event.Threads[0].Frames, 1)
+
+	event, err = core.ParseGoroutines(strings.NewReader(multipleGoroutines))
+	require.NoError(t, err)
+	assert.Equal(t, "Something went wrong in packageA.foo()", event.Panic.Type)
+	assert.Equal(t, "fatal", event.Level)
+
+	_, err = core.ParseGoroutines(strings.NewReader("nothing to see here"))
+	assert.ErrorIs(t, err, core.ErrNoGoroutines)
+}
+
+const cgoAbort = `SIGABRT: abort
+PC=0x7f5b6b5e5c4a m=0 sigcode=18446744073709551610
+signal arrived during cgo execution
+
+goroutine 1 [syscall]:
+runtime.cgocall(0x4805e0, 0xc000067f40)
+	/usr/local/go/src/runtime/cgocall.go:157 +0x4b fp=0xc000067f18 sp=0xc000067ee0 pc=0x40460b
+main._Cfunc_crash()
+	_cgo_gotypes.go:39 +0x45 fp=0xc000067f40 sp=0xc000067f18 pc=0x4804c5
+main.main()
+	/app/main.go:12 +0x17 fp=0xc000067f50 sp=0xc000067f40 pc=0x4804f7
+`
+
+func TestParseEventFault(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(`unexpected fault address 0x7f1e2c000000
+fatal error: fault
+[signal SIGSEGV: segmentation violation code=0x2 addr=0x7f1e2c000000 pc=0x45e1b2]
+
+goroutine 1 [running]:
+runtime.throw({0x4a1d2b?, 0x0?})
+	/usr/local/go/src/runtime/panic.go:1047 +0x5d fp=0xc000067e70 sp=0xc000067e40 pc=0x4340dd
+main.main()
+	/app/main.go:12 +0x17 fp=0xc000067f50 sp=0xc000067f40 pc=0x4804f7`))
+	require.NoError(t, err)
+	require.Empty(t, event.Diagnostics)
+
+	assert.Equal(t, "fault", event.Panic.Type)
+	assert.Equal(t, "0x7f1e2c000000", event.Panic.FaultAddress)
+	assert.Equal(t, "SIGSEGV", event.Panic.Signal)
+	assert.False(t, event.Panic.InCgo)
+	assert.Len(t, event.Threads, 1)
+}
+
+func TestParseEventCgo(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(cgoAbort))
+	require.NoError(t, err)
+	require.Empty(t, event.Diagnostics)
+
+	assert.Equal(t, "SIGABRT", event.Panic.Signal)
+	assert.Equal(t, "0x7f5b6b5e5c4a", event.Panic.PC)
+	assert.True(t, event.Panic.InCgo)
+	assert.Equal(t, "fatal", event.Level)
+	require.Len(t, event.Threads, 1)
+	assert.Len(t, event.Threads[0].Frames, 3)
+
+	event, err = core.ParseEvent(strings.NewReader(`fatal error: unexpected signal during runtime execution
+[signal SIGSEGV: segmentation violation code=0x1 addr=0x0 pc=0x7f5b6b5e5c4a]
+
+signal arrived during cgo execution
+
+goroutine 1 [syscall]:
+main.main()
+	/app/main.go:12 +0x17`))
+	require.NoError(t, err)
+	assert.True(t, event.Panic.InCgo)
+	assert.Len(t, event.Threads, 1)
+}
+
+const multipleGoroutines = `panic: Something went wrong in packageA.foo()
+
+goroutine 1 [running]:
+github.com/user/packageA.foo()
+	/path/to/packageA/foo.go:10
+github.com/user/packageB.bar()
+	/path/to/packageB/bar.go:15
+main.main()
+	/path/to/main.go:8
+
+goroutine 2 [running]:
+main.anotherFunction()
+	/path/to/main.go:20
+created by main.main
+	/path/to/main.go:25`
+
+const noPanic = `goroutine 1 [running]:
+main.main()
+	/tmp/sandbox675251439/main.go:23 +0x314`
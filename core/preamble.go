@@ -0,0 +1,66 @@
+package core
+
+import (
+	"regexp"
+	"strconv"
+)
+
+var (
+	runtimePrefix      = []byte("runtime: ")
+	stackExceedsRegexp = regexp.MustCompile(`^runtime: goroutine stack exceeds (\d+)-byte limit$`)
+	stackBoundsRegexp  = regexp.MustCompile(`^runtime: sp=(0x[0-9a-f]+) stack=\[(0x[0-9a-f]+), (0x[0-9a-f]+)\]$`)
+	outOfMemoryRegexp  = regexp.MustCompile(`^runtime: out of memory: cannot allocate (\d+)-byte block \((\d+) in use\)$`)
+	threadLimitRegexp  = regexp.MustCompile(`^runtime: program exceeds (\d+)-thread limit$`)
+)
+
+// maxPreamble bounds the preamble lines held for an event that has not
+// started yet, which the runtime never prints more than a few of.
+const maxPreamble = 32
+
+// Preamble is the diagnostic output the runtime prints on lines starting
+// "runtime: " before some fatal errors. The numbers are parsed from the
+// well-known lines and are zero when the runtime didn't print them.
+type Preamble struct {
+	Lines []string
+
+	// StackLimit is the maximum stack size in bytes, which the goroutine
+	// exceeded in a stack overflow. SP is its stack pointer at the time and
+	// StackLo and StackHi the bounds of its stack.
+	StackLimit int64
+	SP         uint64
+	StackLo    uint64
+	StackHi    uint64
+
+	// AllocRequested is the size in bytes of the allocation that ran out of
+	// memory and AllocInUse how many bytes were in use.
+	AllocRequested int64
+	AllocInUse     int64
+
+	// ThreadLimit is the limit on OS threads the program exceeded.
+	ThreadLimit int64
+}
+
+// newPreamble parses the preamble lines. Numbers that fail to parse are
+// left at zero, as the raw lines are kept.
+func newPreamble(lines []string) *Preamble {
+	p := &Preamble{
+		Lines: lines,
+	}
+
+	for _, line := range lines {
+		if matches := stackExceedsRegexp.FindStringSubmatch(line); matches != nil {
+			p.StackLimit, _ = strconv.ParseInt(matches[1], 10, 64)
+		} else if matches := stackBoundsRegexp.FindStringSubmatch(line); matches != nil {
+			p.SP, _ = strconv.ParseUint(matches[1], 0, 64)
+			p.StackLo, _ = strconv.ParseUint(matches[2], 0, 64)
+			p.StackHi, _ = strconv.ParseUint(matches[3], 0, 64)
+		} else if matches := outOfMemoryRegexp.FindStringSubmatch(line); matches != nil {
+			p.AllocRequested, _ = strconv.ParseInt(matches[1], 10, 64)
+			p.AllocInUse, _ = strconv.ParseInt(matches[2], 10, 64)
+		} else if matches := threadLimitRegexp.FindStringSubmatch(line); matches != nil {
+			p.ThreadLimit, _ = strconv.ParseInt(matches[1], 10, 64)
+		}
+	}
+
+	return p
+}
@@ -0,0 +1,83 @@
+package core_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/avos-io/panic-parse/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEventPreamble(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(stackOverflow))
+	require.NoError(t, err)
+	require.NotNil(t, event.Preamble)
+
+	assert.Equal(t, []string{
+		"runtime: goroutine stack exceeds 1000000000-byte limit",
+		"runtime: sp=0xc020160398 stack=[0xc020160000, 0xc040160000]",
+	}, event.Preamble.Lines)
+	assert.Equal(t, int64(1000000000), event.Preamble.StackLimit)
+	assert.Equal(t, uint64(0xc020160398), event.Preamble.SP)
+	assert.Equal(t, uint64(0xc020160000), event.Preamble.StackLo)
+	assert.Equal(t, uint64(0xc040160000), event.Preamble.StackHi)
+
+	event, err = core.ParseEvent(strings.NewReader(multipleGoroutines))
+	require.NoError(t, err)
+	assert.Nil(t, event.Preamble)
+}
+
+func TestScannerPreamble(t *testing.T) {
+	scanner := core.NewScanner(strings.NewReader(`fatal error: out of memory
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d
+runtime: program exceeds 10000-thread limit
+fatal error: thread exhaustion
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:20 +0x1d
+
+runtime: out of memory: cannot allocate 8192-byte block (3951132672 in use)
+`))
+
+	event, err := scanner.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "out of memory", event.Panic.Type)
+	assert.Nil(t, event.Preamble)
+
+	event, err = scanner.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "thread exhaustion", event.Panic.Type)
+	require.NotNil(t, event.Preamble)
+	assert.Equal(t, int64(10000), event.Preamble.ThreadLimit)
+
+	// Trailing lines with no header after them stay with the last event
+	assert.Len(t, event.Preamble.Lines, 2)
+	assert.Equal(t, int64(8192), event.Preamble.AllocRequested)
+	assert.Equal(t, int64(3951132672), event.Preamble.AllocInUse)
+}
+
+func TestParseEventPreambleLimit(t *testing.T) {
+	var log strings.Builder
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&log, "runtime: line %d\n", i)
+	}
+	log.WriteString(`fatal error: oh no
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d`)
+
+	event, err := core.ParseEvent(strings.NewReader(log.String()))
+	require.NoError(t, err)
+	require.NotNil(t, event.Preamble)
+
+	// Only the lines closest to the header are kept
+	require.Len(t, event.Preamble.Lines, 32)
+	assert.Equal(t, "runtime: line 99", event.Preamble.Lines[31])
+}
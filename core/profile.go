@@ -0,0 +1,177 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	profileHeaderRegexp = regexp.MustCompile(`^goroutine profile: total (\d+)$`)
+	profileStackRegexp  = regexp.MustCompile(`^(\d+) @(?: 0x[0-9a-f]+)*$`)
+	profileLabelsRegexp = regexp.MustCompile(`^# labels: \{(.*)\}$`)
+	profileLabelRegexp  = regexp.MustCompile(`"((?:[^"\\]|\\.)*)":"((?:[^"\\]|\\.)*)"`)
+	profileFrameRegexp  = regexp.MustCompile(`^#\t+(0x[0-9a-f]+)(?:\t+(.+?)\+(0x[0-9a-f]+)\t+(.+):(\d+))?$`)
+)
+
+// GoroutineProfile is the Type of the synthetic panic given to goroutine
+// profiles.
+const GoroutineProfile = "goroutine profile"
+
+// ParseProfile parses a goroutine profile as served by net/http/pprof at
+// /debug/pprof/goroutine, in either the aggregated debug=1 format or the
+// debug=2 format, which matches a panic's goroutine dump. The profile is
+// given a synthetic GoroutineProfile panic at level "info".
+//
+// In the debug=1 format each Goroutine is a distinct stack shared by Count
+// goroutines and has no ID or State. If the profile contains no goroutines a
+// *ParseError wrapping ErrNoGoroutines is returned.
+func ParseProfile(profile io.Reader) (*Event, error) {
+	reader := bufio.NewReader(profile)
+
+	first, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if matches := profileHeaderRegexp.FindStringSubmatch(strings.TrimRight(first, "\r\n")); matches != nil {
+		return parseAggregatedProfile(reader, matches[1])
+	}
+
+	event, err := ParseGoroutines(io.MultiReader(strings.NewReader(first), reader))
+	if err != nil {
+		return nil, err
+	}
+
+	event.Panic = &Panic{
+		Type:        GoroutineProfile,
+		Description: fmt.Sprintf("total %d", len(event.Threads)),
+		Synthetic:   true,
+	}
+	event.Chain = []*Panic{event.Panic}
+	event.Level = "info"
+
+	return event, nil
+}
+
+// parseAggregatedProfile parses the debug=1 format after its header line.
+func parseAggregatedProfile(r io.Reader, total string) (*Event, error) {
+	panic := &Panic{
+		Type:        GoroutineProfile,
+		Description: "total " + total,
+		Synthetic:   true,
+	}
+	event := &Event{
+		Panic: panic,
+		Chain: []*Panic{panic},
+		Level: "info",
+	}
+
+	diagnose := func(lineNo int, line string, err error) {
+		event.Diagnostics = append(event.Diagnostics, &ParseError{
+			Line:  lineNo,
+			Raw:   line,
+			State: "profile",
+			Err:   err,
+		})
+	}
+
+	var stack *Goroutine
+
+	scanner := bufio.NewScanner(r)
+	// The header was line 1
+	for lineNo := 2; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+
+		if matches := profileStackRegexp.FindStringSubmatch(line); matches != nil {
+			count, err := strconv.Atoi(matches[1])
+			if err != nil {
+				diagnose(lineNo, line, fmt.Errorf("failed to parse count: %w", err))
+			}
+
+			stack = &Goroutine{
+				Count: count,
+			}
+			event.Threads = append(event.Threads, stack)
+			continue
+		}
+
+		if stack == nil {
+			continue
+		}
+
+		if matches := profileLabelsRegexp.FindStringSubmatch(line); matches != nil {
+			stack.Labels = map[string]string{}
+			for _, label := range profileLabelRegexp.FindAllStringSubmatch(matches[1], -1) {
+				key, err := strconv.Unquote(`"` + label[1] + `"`)
+				if err != nil {
+					diagnose(lineNo, line, fmt.Errorf("failed to parse label: %w", err))
+					continue
+				}
+				value, err := strconv.Unquote(`"` + label[2] + `"`)
+				if err != nil {
+					diagnose(lineNo, line, fmt.Errorf("failed to parse label: %w", err))
+					continue
+				}
+				stack.Labels[key] = value
+			}
+			continue
+		}
+
+		matches := profileFrameRegexp.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+
+		frame := &Frame{}
+
+		var err error
+		frame.PC, err = strconv.ParseUint(matches[1], 0, 64)
+		if err != nil {
+			diagnose(lineNo, line, fmt.Errorf("failed to parse program counter: %w", err))
+		}
+
+		// The runtime prints only the program counter when it has no
+		// symbol for it
+		if matches[2] != "" {
+			sym := decodeSymbol(matches[2])
+			frame.RawFunc = matches[2]
+			frame.Package = sym.Package
+			frame.Pointer = sym.Pointer
+			frame.Receiver = sym.Receiver
+			frame.Func = sym.Func
+			frame.TypeParams = sym.TypeParams
+			frame.ClosurePath = sym.ClosurePath
+			frame.MethodValue = sym.MethodValue
+			frame.File = matches[4]
+
+			frame.StackOffset, err = strconv.ParseInt(matches[3], 0, 64)
+			if err != nil {
+				diagnose(lineNo, line, fmt.Errorf("failed to parse stack offset: %w", err))
+			}
+
+			frame.Line, err = strconv.Atoi(matches[5])
+			if err != nil {
+				diagnose(lineNo, line, fmt.Errorf("failed to parse line number: %w", err))
+			}
+		}
+
+		stack.Frames = append(stack.Frames, frame)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(event.Threads) == 0 {
+		return nil, &ParseError{
+			State: "profile",
+			Err:   ErrNoGoroutines,
+		}
+	}
+
+	return event, nil
+}
@@ -0,0 +1,78 @@
+package core_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/avos-io/panic-parse/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const aggregatedProfile = "goroutine profile: total 13\n" +
+	"12 @ 0x43a0b6 0x4066ab 0x6ad4b9 0x46a7a1\n" +
+	"# labels: {\"handler\":\"/api/items\", \"method\":\"GET\"}\n" +
+	"#\t0x4066aa\truntime.chanrecv1+0x1a\t\t\t/usr/local/go/src/runtime/chan.go:442\n" +
+	"#\t0x6ad4b8\tmain.(*Server).worker+0x98\t\t/app/server.go:12\n" +
+	"\n" +
+	"1 @ 0x46a7a1 0x12345\n" +
+	"#\t0x46a7a0\truntime.goexit+0x0\t/usr/local/go/src/runtime/asm_amd64.s:1650\n" +
+	"#\t0x12345\n"
+
+func TestParseProfileAggregated(t *testing.T) {
+	event, err := core.ParseProfile(strings.NewReader(aggregatedProfile))
+	require.NoError(t, err)
+	require.Empty(t, event.Diagnostics)
+
+	assert.Equal(t, core.GoroutineProfile, event.Panic.Type)
+	assert.Equal(t, "total 13", event.Panic.Description)
+	assert.Equal(t, "info", event.Level)
+
+	require.Len(t, event.Threads, 2)
+
+	stack := event.Threads[0]
+	assert.Equal(t, 12, stack.Count)
+	assert.Empty(t, stack.ID)
+	assert.Equal(t, map[string]string{"handler": "/api/items", "method": "GET"}, stack.Labels)
+	require.Len(t, stack.Frames, 2)
+
+	frame := stack.Frames[1]
+	assert.Equal(t, "main", frame.Package)
+	assert.Equal(t, "Server", frame.Receiver)
+	assert.True(t, frame.Pointer)
+	assert.Equal(t, "worker", frame.Func)
+	assert.Equal(t, "/app/server.go", frame.File)
+	assert.Equal(t, 12, frame.Line)
+	assert.Equal(t, int64(0x98), frame.StackOffset)
+	assert.Equal(t, uint64(0x6ad4b8), frame.PC)
+
+	stack = event.Threads[1]
+	assert.Equal(t, 1, stack.Count)
+	assert.Nil(t, stack.Labels)
+	require.Len(t, stack.Frames, 2)
+	assert.Equal(t, "goexit", stack.Frames[0].Func)
+	assert.Empty(t, stack.Frames[1].Func)
+	assert.Equal(t, uint64(0x12345), stack.Frames[1].PC)
+}
+
+func TestParseProfileFull(t *testing.T) {
+	event, err := core.ParseProfile(strings.NewReader(`goroutine 1 [chan receive]:
+main.main()
+	/app/main.go:10 +0x1d
+
+goroutine 7 [select, 2 minutes]:
+main.worker()
+	/app/main.go:20 +0x2d
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, core.GoroutineProfile, event.Panic.Type)
+	assert.Equal(t, "total 2", event.Panic.Description)
+	assert.Equal(t, "info", event.Level)
+	require.Len(t, event.Threads, 2)
+	assert.Equal(t, "7", event.Threads[1].ID)
+	assert.Zero(t, event.Threads[1].Count)
+
+	_, err = core.ParseProfile(strings.NewReader("goroutine profile: total 0\n"))
+	assert.ErrorIs(t, err, core.ErrNoGoroutines)
+}
@@ -0,0 +1,165 @@
+package core
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RuntimeErrorKind is the kind of a panic raised by the runtime itself.
+type RuntimeErrorKind string
+
+const (
+	KindIndexOutOfRange     RuntimeErrorKind = "index out of range"
+	KindSliceOutOfRange     RuntimeErrorKind = "slice bounds out of range"
+	KindNilMapWrite         RuntimeErrorKind = "assignment to entry in nil map"
+	KindDivideByZero        RuntimeErrorKind = "integer divide by zero"
+	KindNilDereference      RuntimeErrorKind = "nil pointer dereference"
+	KindInterfaceConversion RuntimeErrorKind = "interface conversion"
+	KindNegativeShift       RuntimeErrorKind = "negative shift amount"
+	KindMakeSliceLen        RuntimeErrorKind = "makeslice: len out of range"
+	KindMakeSliceCap        RuntimeErrorKind = "makeslice: cap out of range"
+	KindSendOnClosed        RuntimeErrorKind = "send on closed channel"
+	KindCloseOfClosed       RuntimeErrorKind = "close of closed channel"
+	KindCloseOfNil          RuntimeErrorKind = "close of nil channel"
+)
+
+const runtimeErrorPrefix = "runtime error: "
+
+var (
+	indexRegexp        = regexp.MustCompile(`^index out of range \[(-?\d+)\](?: with length (\d+))?$`)
+	sliceRegexp        = regexp.MustCompile(`^slice bounds out of range (\[[^\]]*\])(?: with (length|capacity) (\d+))?$`)
+	numberRegexp       = regexp.MustCompile(`-?\d+`)
+	convertRegexp      = regexp.MustCompile(`^interface conversion: (.+) is (.+), not (.+?)(?: \(types from different (?:packages|scopes)\))?$`)
+	missingRegexp      = regexp.MustCompile(`^interface conversion: (.+) is not (.+): missing method (.+)$`)
+	fixedRuntimeErrors = map[string]RuntimeErrorKind{
+		"integer divide by zero":                            KindDivideByZero,
+		"invalid memory address or nil pointer dereference": KindNilDereference,
+		"negative shift amount":                             KindNegativeShift,
+		"makeslice: len out of range":                       KindMakeSliceLen,
+		"makeslice: cap out of range":                       KindMakeSliceCap,
+	}
+
+	// plainErrors are the fixed messages the runtime raises without the
+	// "runtime error: " prefix
+	plainErrors = map[string]RuntimeErrorKind{
+		"assignment to entry in nil map": KindNilMapWrite,
+		"send on closed channel":         KindSendOnClosed,
+		"close of closed channel":        KindCloseOfClosed,
+		"close of nil channel":           KindCloseOfNil,
+	}
+)
+
+// RuntimeError is a panic raised by the runtime, classified from its
+// message.
+type RuntimeError struct {
+	Kind RuntimeErrorKind
+
+	// Message is the runtime's message without its "runtime error: "
+	// prefix.
+	Message string
+
+	// Index is the index that was out of range, or the first bound printed
+	// for slice expressions, and Expr the expression as printed, such as
+	// "[:5]" or "[4:2]". Length and Capacity are the length or capacity it
+	// was checked against. Each is -1 when the runtime didn't print it.
+	Index    int
+	Expr     string
+	Length   int
+	Capacity int
+
+	// Interface conversions give the static type of the converted value
+	// (Interface) or its dynamic type (Concrete), the type it was converted
+	// to (Expected) and, if it didn't implement that interface, a method
+	// it is missing.
+	Interface string
+	Concrete  string
+	Expected  string
+	Missing   string
+}
+
+// Classify recognizes the messages of the runtime's own panics. They must
+// have the "runtime error: " prefix the runtime gives them, so that a
+// program's own panics with the same text aren't mistaken for them, except
+// for those it raises without: interface conversions and plain errors such as
+// "send on closed channel". It returns nil for any other message.
+func Classify(message string) *RuntimeError {
+	prefixed := strings.HasPrefix(message, runtimeErrorPrefix)
+	message = strings.TrimPrefix(message, runtimeErrorPrefix)
+
+	e := &RuntimeError{
+		Message:  message,
+		Index:    -1,
+		Length:   -1,
+		Capacity: -1,
+	}
+
+	if kind, ok := plainErrors[message]; ok {
+		e.Kind = kind
+		return e
+	}
+
+	if matches := missingRegexp.FindStringSubmatch(message); matches != nil {
+		e.Kind = KindInterfaceConversion
+		e.Concrete = matches[1]
+		e.Expected = matches[2]
+		e.Missing = matches[3]
+		return e
+	}
+
+	if matches := convertRegexp.FindStringSubmatch(message); matches != nil {
+		e.Kind = KindInterfaceConversion
+		e.Interface = matches[1]
+		e.Concrete = matches[2]
+		e.Expected = matches[3]
+		return e
+	}
+
+	if !prefixed {
+		return nil
+	}
+
+	if kind, ok := fixedRuntimeErrors[message]; ok {
+		e.Kind = kind
+		return e
+	}
+
+	if matches := indexRegexp.FindStringSubmatch(message); matches != nil {
+		e.Kind = KindIndexOutOfRange
+		e.Index = atoiOr(matches[1], -1)
+		e.Expr = "[" + matches[1] + "]"
+		e.Length = atoiOr(matches[2], -1)
+		return e
+	}
+
+	if matches := sliceRegexp.FindStringSubmatch(message); matches != nil {
+		e.Kind = KindSliceOutOfRange
+		e.Expr = matches[1]
+		e.Index = atoiOr(numberRegexp.FindString(matches[1]), -1)
+		switch matches[2] {
+		case "length":
+			e.Length = atoiOr(matches[3], -1)
+		case "capacity":
+			e.Capacity = atoiOr(matches[3], -1)
+		}
+		return e
+	}
+
+	return nil
+}
+
+// message returns the panic's message as the runtime printed it.
+func (p *Panic) message() string {
+	if p.Description == "" {
+		return p.Type
+	}
+	return p.Type + ": " + p.Description
+}
+
+func atoiOr(s string, fallback int) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fallback
+	}
+	return n
+}
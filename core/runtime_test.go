@@ -0,0 +1,130 @@
+package core_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/avos-io/panic-parse/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	cases := map[string]core.RuntimeError{
+		"runtime error: index out of range [5] with length 3": {
+			Kind: core.KindIndexOutOfRange, Message: "index out of range [5] with length 3",
+			Index: 5, Expr: "[5]", Length: 3, Capacity: -1,
+		},
+		"runtime error: index out of range [-1]": {
+			Kind: core.KindIndexOutOfRange, Message: "index out of range [-1]",
+			Index: -1, Expr: "[-1]", Length: -1, Capacity: -1,
+		},
+		"runtime error: slice bounds out of range [:8] with capacity 4": {
+			Kind: core.KindSliceOutOfRange, Message: "slice bounds out of range [:8] with capacity 4",
+			Index: 8, Expr: "[:8]", Length: -1, Capacity: 4,
+		},
+		"runtime error: slice bounds out of range [5:2]": {
+			Kind: core.KindSliceOutOfRange, Message: "slice bounds out of range [5:2]",
+			Index: 5, Expr: "[5:2]", Length: -1, Capacity: -1,
+		},
+		"assignment to entry in nil map": {
+			Kind: core.KindNilMapWrite, Message: "assignment to entry in nil map",
+			Index: -1, Length: -1, Capacity: -1,
+		},
+		"runtime error: integer divide by zero": {
+			Kind: core.KindDivideByZero, Message: "integer divide by zero",
+			Index: -1, Length: -1, Capacity: -1,
+		},
+		"runtime error: negative shift amount": {
+			Kind: core.KindNegativeShift, Message: "negative shift amount",
+			Index: -1, Length: -1, Capacity: -1,
+		},
+		"runtime error: makeslice: len out of range": {
+			Kind: core.KindMakeSliceLen, Message: "makeslice: len out of range",
+			Index: -1, Length: -1, Capacity: -1,
+		},
+		"send on closed channel": {
+			Kind: core.KindSendOnClosed, Message: "send on closed channel",
+			Index: -1, Length: -1, Capacity: -1,
+		},
+		"close of closed channel": {
+			Kind: core.KindCloseOfClosed, Message: "close of closed channel",
+			Index: -1, Length: -1, Capacity: -1,
+		},
+		"runtime error: close of nil channel": {
+			Kind: core.KindCloseOfNil, Message: "close of nil channel",
+			Index: -1, Length: -1, Capacity: -1,
+		},
+		"interface conversion: interface {} is string, not int": {
+			Kind: core.KindInterfaceConversion, Message: "interface conversion: interface {} is string, not int",
+			Index: -1, Length: -1, Capacity: -1,
+			Interface: "interface {}", Concrete: "string", Expected: "int",
+		},
+		"interface conversion: interface {} is main.T, not main.T (types from different packages)": {
+			Kind: core.KindInterfaceConversion, Message: "interface conversion: interface {} is main.T, not main.T (types from different packages)",
+			Index: -1, Length: -1, Capacity: -1,
+			Interface: "interface {}", Concrete: "main.T", Expected: "main.T",
+		},
+		"interface conversion: *main.T is not io.Reader: missing method Read": {
+			Kind: core.KindInterfaceConversion, Message: "interface conversion: *main.T is not io.Reader: missing method Read",
+			Index: -1, Length: -1, Capacity: -1,
+			Concrete: "*main.T", Expected: "io.Reader", Missing: "Read",
+		},
+	}
+
+	for message, expected := range cases {
+		t.Run(message, func(t *testing.T) {
+			actual := core.Classify(message)
+			require.NotNil(t, actual)
+			assert.Equal(t, expected, *actual)
+		})
+	}
+
+	assert.Nil(t, core.Classify("oh no"))
+	assert.Nil(t, core.Classify("runtime error: something new"))
+}
+
+func TestClassifyUserPanics(t *testing.T) {
+	// Programs may panic with the runtime's words, but only the runtime adds
+	// the prefix
+	for _, message := range []string{
+		"index out of range [3] with length 2",
+		"slice bounds out of range [:8] with capacity 4",
+		"integer divide by zero",
+		"invalid memory address or nil pointer dereference",
+		"negative shift amount",
+		"makeslice: len out of range",
+	} {
+		assert.Nil(t, core.Classify(message), message)
+		assert.NotNil(t, core.Classify("runtime error: "+message), message)
+	}
+
+	event, err := core.ParseEvent(strings.NewReader(`panic: integer divide by zero
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d`))
+	require.NoError(t, err)
+	assert.Nil(t, event.Panic.Runtime)
+}
+
+func TestParseEventRuntimeError(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(`panic: runtime error: invalid memory address or nil pointer dereference
+[signal SIGSEGV: segmentation violation code=0x1 addr=0x0 pc=0x457d1c]
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d`))
+	require.NoError(t, err)
+
+	require.NotNil(t, event.Panic.Runtime)
+	assert.Equal(t, core.KindNilDereference, event.Panic.Runtime.Kind)
+
+	event, err = core.ParseEvent(strings.NewReader(`panic: oh no
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d`))
+	require.NoError(t, err)
+	assert.Nil(t, event.Panic.Runtime)
+}
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"bufio"
+	"io"
+)
+
+// Scanner reads successive events from a log that may contain several
+// panics, such as the output of a service that has crashed and been
+// restarted. Each panic or fatal error header starts a new event.
+type Scanner struct {
+	scanner *bufio.Scanner
+	parser  parser
+}
+
+// NewScanner returns a Scanner reading from r.
+func NewScanner(r io.Reader) *Scanner {
+	return &Scanner{
+		scanner: bufio.NewScanner(r),
+	}
+}
+
+// Next returns the next event in the log. It returns io.EOF once the log is
+// exhausted.
+func (s *Scanner) Next() (*Event, error) {
+	for s.scanner.Scan() {
+		if event := s.parser.feed(s.scanner.Bytes()); event != nil {
+			return event, nil
+		}
+	}
+
+	if err := s.scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if event := s.parser.finish(); event != nil {
+		return event, nil
+	}
+
+	return nil, io.EOF
+}
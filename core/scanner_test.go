@@ -0,0 +1,66 @@
+package core_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/avos-io/panic-parse/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanner(t *testing.T) {
+	scanner := core.NewScanner(strings.NewReader(`starting server
+panic: first
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d
+exit status 2
+starting server
+fatal error: all goroutines are asleep - deadlock!
+
+goroutine 1 [chan receive]:
+main.main()
+	/app/main.go:20 +0x2d
+starting server
+panic: third
+
+goroutine 7 [running]:
+main.worker()
+	/app/main.go:30 +0x3d
+created by main.main
+	/app/main.go:25 +0x4d
+
+goroutine 1 [select]:
+main.main()
+	/app/main.go:26 +0x5d
+`))
+
+	expected := []struct {
+		Type    string
+		Threads []string
+	}{
+		{"first", []string{"1"}},
+		{"all goroutines are asleep - deadlock!", []string{"1"}},
+		{"third", []string{"7", "1"}},
+	}
+
+	for _, e := range expected {
+		event, err := scanner.Next()
+		require.NoError(t, err)
+
+		assert.Equal(t, e.Type, event.Panic.Type)
+
+		ids := []string{}
+		for _, g := range event.Threads {
+			ids = append(ids, g.ID)
+		}
+		assert.Equal(t, e.Threads, ids)
+	}
+
+	event, err := scanner.Next()
+	assert.Nil(t, event)
+	assert.Equal(t, io.EOF, err)
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+// Stream is a push-based parser for logs that never reach EOF, such as a
+// tailed log file. Lines are fed in as they arrive and each event is passed to
+// the handler as soon as its trace is provably over: when a new panic starts,
+// when a blank line is followed by something that is not part of a trace, or
+// when no line has arrived for the idle timeout.
+type Stream struct {
+	mu      sync.Mutex
+	parser  parser
+	handler func(*Event)
+
+	idle  time.Duration
+	timer *time.Timer
+	seq   uint64
+	blank bool
+}
+
+// NewStream returns a Stream that passes each completed event to handler.
+// An idle timeout of zero or less disables timing out; the final event is
+// then only emitted by a following line or by Flush.
+//
+// The handler is called with the stream locked, so it must not call Feed or
+// Flush itself.
+func NewStream(handler func(*Event), idle time.Duration) *Stream {
+	return &Stream{
+		handler: handler,
+		idle:    idle,
+	}
+}
+
+// Feed processes a single line of the log. A trailing newline is ignored.
+func (s *Stream) Feed(line []byte) {
+	line = bytes.TrimRight(line, "\r\n")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+
+	blank := len(bytes.TrimSpace(line)) == 0
+	if s.blank && !blank && s.parser.event != nil && !s.parser.continues(line) {
+		s.emit(s.parser.finish())
+	}
+	s.blank = blank
+
+	s.emit(s.parser.feed(line))
+
+	// Preamble lines held for an event that never starts expire too
+	if s.idle > 0 && (s.parser.event != nil || s.parser.pending()) {
+		if s.timer != nil {
+			s.timer.Stop()
+		}
+		seq := s.seq
+		s.timer = time.AfterFunc(s.idle, func() {
+			s.timeout(seq)
+		})
+	}
+}
+
+// Flush emits the event in progress, if any.
+func (s *Stream) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.emit(s.parser.finish())
+}
+
+func (s *Stream) timeout(seq uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Another line arrived after the timer was set
+	if seq != s.seq {
+		return
+	}
+	s.emit(s.parser.finish())
+}
+
+func (s *Stream) emit(event *Event) {
+	if event != nil {
+		s.handler(event)
+	}
+}
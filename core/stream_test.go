@@ -0,0 +1,152 @@
+package core_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avos-io/panic-parse/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStream(t *testing.T) {
+	events := []*core.Event{}
+	stream := core.NewStream(func(e *core.Event) {
+		events = append(events, e)
+	}, 0)
+
+	feed := func(log string) {
+		for _, line := range strings.Split(log, "\n") {
+			stream.Feed([]byte(line + "\n"))
+		}
+	}
+
+	feed(`panic: oh no
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d
+
+goroutine 2 [select]:
+main.worker()
+	/app/main.go:20 +0x2d
+`)
+	assert.Empty(t, events, "trace may not be over yet")
+
+	feed("rip    0x46f2a1\n")
+	assert.Empty(t, events, "registers are part of the trace")
+
+	feed("starting server")
+	require.Len(t, events, 1)
+	assert.Equal(t, "oh no", events[0].Panic.Type)
+	assert.Len(t, events[0].Threads, 2)
+	assert.Equal(t, uint64(0x46f2a1), events[0].Panic.Registers["rip"])
+
+	feed(`panic: again
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d`)
+	assert.Len(t, events, 1)
+
+	stream.Flush()
+	require.Len(t, events, 2)
+	assert.Equal(t, "again", events[1].Panic.Type)
+}
+
+func TestStreamAbortAfterPanic(t *testing.T) {
+	events := []*core.Event{}
+	stream := core.NewStream(func(e *core.Event) {
+		events = append(events, e)
+	}, 0)
+
+	for _, line := range strings.Split(panicWithAbort, "\n") {
+		stream.Feed([]byte(line))
+	}
+	assert.Empty(t, events, "the abort block is part of the trace")
+
+	stream.Flush()
+	require.Len(t, events, 1)
+	assert.Len(t, events[0].Threads, 2)
+}
+
+func TestStreamRelayedSignals(t *testing.T) {
+	events := []*core.Event{}
+	stream := core.NewStream(func(e *core.Event) {
+		events = append(events, e)
+	}, 0)
+
+	for _, line := range strings.Split(cgoCrash+relayedBlocks, "\n") {
+		stream.Feed([]byte(line))
+	}
+	assert.Empty(t, events, "the relayed blocks are part of the trace")
+
+	stream.Flush()
+	require.Len(t, events, 1)
+	assert.Len(t, events[0].Threads, 3)
+}
+
+func TestStreamIdleTimeout(t *testing.T) {
+	events := make(chan *core.Event, 1)
+	stream := core.NewStream(func(e *core.Event) {
+		events <- e
+	}, 10*time.Millisecond)
+
+	for _, line := range strings.Split(`panic: oh no
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d`, "\n") {
+		stream.Feed([]byte(line))
+	}
+
+	select {
+	case event := <-events:
+		assert.Equal(t, "oh no", event.Panic.Type)
+		assert.Len(t, event.Threads, 1)
+	case <-time.After(time.Second):
+		t.Fatal("event not emitted after idle timeout")
+	}
+}
+
+func TestStreamStalePreamble(t *testing.T) {
+	events := []*core.Event{}
+	stream := core.NewStream(func(e *core.Event) {
+		events = append(events, e)
+	}, 10*time.Millisecond)
+
+	crash := func() {
+		for _, line := range strings.Split(`fatal error: out of memory
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d`, "\n") {
+			stream.Feed([]byte(line))
+		}
+		stream.Flush()
+	}
+
+	// A blank line separates stray lines from the next crash
+	stream.Feed([]byte("runtime: out of memory: cannot allocate 8192-byte block (3951132672 in use)"))
+	stream.Feed([]byte(""))
+	crash()
+	require.Len(t, events, 1)
+	assert.Nil(t, events[0].Preamble)
+
+	// So does the idle timeout
+	stream.Feed([]byte("unexpected fault address 0x7f1e2c000000"))
+	stream.Feed([]byte("runtime: out of memory: cannot allocate 8192-byte block (3951132672 in use)"))
+	time.Sleep(50 * time.Millisecond)
+	crash()
+	require.Len(t, events, 2)
+	assert.Nil(t, events[1].Preamble)
+	assert.Empty(t, events[1].Panic.FaultAddress)
+
+	// Lines straight before the crash are still its preamble
+	stream.Feed([]byte("runtime: out of memory: cannot allocate 8192-byte block (3951132672 in use)"))
+	crash()
+	require.Len(t, events, 3)
+	require.NotNil(t, events[2].Preamble)
+	assert.Equal(t, int64(8192), events[2].Preamble.AllocRequested)
+}
@@ -0,0 +1,195 @@
+package core
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var closureRegexp = regexp.MustCompile(`^(?:func\d+|gowrap\d+|deferwrap\d+|\d+)$`)
+
+// symbol is a Go function name decoded into its parts.
+type symbol struct {
+	Package     string
+	Receiver    string
+	Pointer     bool
+	Func        string
+	TypeParams  []string
+	ClosurePath []string
+	MethodValue bool
+}
+
+// splitFuncLine splits a traceback function line into the function name and
+// the text between the argument parentheses. ok is false if the line does not
+// look like a Go function call.
+func splitFuncLine(line string) (name string, args string, ok bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasSuffix(line, ")") {
+		return "", "", false
+	}
+
+	depth := 0
+	for i := len(line) - 1; i >= 0; i-- {
+		switch line[i] {
+		case ')':
+			depth++
+		case '(':
+			depth--
+			if depth == 0 {
+				name = line[:i]
+				if !validSymbol(name) {
+					return "", "", false
+				}
+				return name, line[i+1 : len(line)-1], true
+			}
+		}
+	}
+
+	return "", "", false
+}
+
+// validSymbol reports whether name could be a Go function name.
+func validSymbol(name string) bool {
+	return name != "" && !strings.ContainsAny(name, " \t")
+}
+
+// decodeSymbol decodes a function name as printed by the runtime, such as
+// gopkg.in/yaml%2ev3.(*decoder).unmarshal or pkg.(*List[...]).Push.func1.
+func decodeSymbol(name string) symbol {
+	var sym symbol
+
+	// The package path ends at the first dot after its last slash. Type
+	// parameter lists may contain slashes of their own.
+	start := 0
+	if slash := lastIndexTop(name, '/'); slash >= 0 {
+		start = slash + 1
+	}
+	rest := name
+	if dot := strings.IndexByte(name[start:], '.'); dot >= 0 {
+		sym.Package = unescapePath(name[:start+dot])
+		rest = name[start+dot+1:]
+	}
+
+	if strings.HasSuffix(rest, "-fm") {
+		sym.MethodValue = true
+		rest = strings.TrimSuffix(rest, "-fm")
+	}
+
+	parts := splitTop(rest, '.')
+
+	// Closures inside an inlined method are named after the function they
+	// were inlined into, so only the last receiver counts.
+	for i := len(parts) - 1; i >= 0; i-- {
+		if strings.HasPrefix(parts[i], "(") && strings.HasSuffix(parts[i], ")") {
+			receiver := parts[i][1 : len(parts[i])-1]
+			if strings.HasPrefix(receiver, "*") {
+				sym.Pointer = true
+				receiver = receiver[1:]
+			}
+			sym.Receiver, sym.TypeParams = splitTypeParams(receiver)
+			parts = parts[i+1:]
+			break
+		}
+	}
+
+	switch {
+	case len(parts) == 0:
+	case sym.Receiver != "":
+		// Already have the receiver
+	case len(parts) > 1 && parts[0] == "glob" && parts[1] == "":
+		// Closures in package-level variable initialisers
+		parts = append([]string{"glob."}, parts[2:]...)
+	case len(parts) > 1 && parts[0] == "init" && closureRegexp.MatchString(parts[1]):
+		// Numbered package init functions
+		parts = append([]string{"init." + parts[1]}, parts[2:]...)
+	case len(parts) > 1 && !closureRegexp.MatchString(parts[1]):
+		// Value receivers are printed without parentheses
+		sym.Receiver, sym.TypeParams = splitTypeParams(parts[0])
+		parts = parts[1:]
+	}
+
+	if len(parts) > 0 {
+		var typeParams []string
+		sym.Func, typeParams = splitTypeParams(parts[0])
+		if typeParams != nil {
+			sym.TypeParams = typeParams
+		}
+		if len(parts) > 1 {
+			sym.ClosurePath = parts[1:]
+		}
+	}
+
+	return sym
+}
+
+// splitTypeParams splits a name such as Map[go.shape.int,go.shape.string]
+// into the name and its type parameters.
+func splitTypeParams(name string) (string, []string) {
+	open := strings.IndexByte(name, '[')
+	if open < 0 || !strings.HasSuffix(name, "]") {
+		return name, nil
+	}
+
+	params := splitTop(name[open+1:len(name)-1], ',')
+	for i, param := range params {
+		params[i] = strings.TrimSpace(param)
+	}
+
+	return name[:open], params
+}
+
+// splitTop splits s around each sep that is not inside parentheses or
+// brackets.
+func splitTop(s string, sep byte) []string {
+	parts := []string{}
+	depth := 0
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// lastIndexTop returns the index of the last c in s that is not inside
+// brackets, or -1.
+func lastIndexTop(s string, c byte) int {
+	depth := 0
+	last := -1
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case c:
+			if depth == 0 {
+				last = i
+			}
+		}
+	}
+	return last
+}
+
+// unescapePath undoes the escaping the linker applies to the last element
+// of an import path, such as yaml%2ev3 for yaml.v3.
+func unescapePath(path string) string {
+	if !strings.Contains(path, "%") {
+		return path
+	}
+
+	unescaped, err := url.PathUnescape(path)
+	if err != nil {
+		return path
+	}
+	return unescaped
+}
@@ -0,0 +1,119 @@
+package core_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/avos-io/panic-parse/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSymbols(t *testing.T) {
+	testCases := map[string]struct {
+		Line  string
+		Frame core.Frame
+		Name  string
+	}{
+		"function": {
+			Line:  "main.main()",
+			Frame: core.Frame{Package: "main", Func: "main"},
+			Name:  "main",
+		},
+		"no package": {
+			Line:  "panic(0x112c00, 0x1040a038)",
+			Frame: core.Frame{Func: "panic"},
+			Name:  "panic",
+		},
+		"escaped import path": {
+			Line:  "gopkg.in/yaml%2ev3.(*decoder).unmarshal(0xc000134000, 0xc00013e0a0)",
+			Frame: core.Frame{Package: "gopkg.in/yaml.v3", Receiver: "decoder", Pointer: true, Func: "unmarshal"},
+			Name:  "decoder.unmarshal",
+		},
+		"value receiver": {
+			Line:  "github.com/user/pkg.Config.Validate(...)",
+			Frame: core.Frame{Package: "github.com/user/pkg", Receiver: "Config", Func: "Validate"},
+			Name:  "Config.Validate",
+		},
+		"generic function": {
+			Line:  "github.com/user/pkg.Map[...]({0xc000010000, 0x3, 0x3}, 0x4b2f18)",
+			Frame: core.Frame{Package: "github.com/user/pkg", Func: "Map", TypeParams: []string{"..."}},
+			Name:  "Map",
+		},
+		"generic receiver": {
+			Line:  "github.com/user/pkg.(*List[go.shape.int]).Push(0xc000010000, 0x1)",
+			Frame: core.Frame{Package: "github.com/user/pkg", Receiver: "List", Pointer: true, Func: "Push", TypeParams: []string{"go.shape.int"}},
+			Name:  "List.Push",
+		},
+		"generic value receiver": {
+			Line:  "github.com/user/pkg.Pair[go.shape.int,go.shape.string].Swap(...)",
+			Frame: core.Frame{Package: "github.com/user/pkg", Receiver: "Pair", Func: "Swap", TypeParams: []string{"go.shape.int", "go.shape.string"}},
+			Name:  "Pair.Swap",
+		},
+		"closure": {
+			Line:  "github.com/avos-io/iona/cwauth.Verify.func1({0x1419348, 0xc00035b800})",
+			Frame: core.Frame{Package: "github.com/avos-io/iona/cwauth", Func: "Verify", ClosurePath: []string{"func1"}},
+			Name:  "Verify.func1",
+		},
+		"nested method closure": {
+			Line:  "google.golang.org/grpc.(*Server).serveStreams.func1.1()",
+			Frame: core.Frame{Package: "google.golang.org/grpc", Receiver: "Server", Pointer: true, Func: "serveStreams", ClosurePath: []string{"func1", "1"}},
+			Name:  "Server.serveStreams.func1.1",
+		},
+		"package variable closure": {
+			Line:  "github.com/user/pkg.glob..func1.2()",
+			Frame: core.Frame{Package: "github.com/user/pkg", Func: "glob.", ClosurePath: []string{"func1", "2"}},
+			Name:  "glob..func1.2",
+		},
+		"inlined method closure": {
+			Line:  "github.com/rs/zerolog/log.Panic.(*Logger).Panic.func1({0x2705549?, 0x0?})",
+			Frame: core.Frame{Package: "github.com/rs/zerolog/log", Receiver: "Logger", Pointer: true, Func: "Panic", ClosurePath: []string{"func1"}},
+			Name:  "Logger.Panic.func1",
+		},
+		"method value": {
+			Line:  "net/http.(*Server).Serve-fm(0xc0000a6000)",
+			Frame: core.Frame{Package: "net/http", Receiver: "Server", Pointer: true, Func: "Serve", MethodValue: true},
+			Name:  "Server.Serve",
+		},
+		"go statement wrapper": {
+			Line:  "main.main.gowrap1()",
+			Frame: core.Frame{Package: "main", Func: "main", ClosurePath: []string{"gowrap1"}},
+			Name:  "main.gowrap1",
+		},
+		"init function": {
+			Line:  "main.init.0()",
+			Frame: core.Frame{Package: "main", Func: "init.0"},
+			Name:  "init.0",
+		},
+	}
+
+	for name, tc := range testCases {
+		c := tc
+		t.Run(name, func(t *testing.T) {
+			event, err := core.ParseEvent(strings.NewReader("panic: oh no\n\ngoroutine 1 [running]:\n" + c.Line + "\n\t/app/main.go:10 +0x1d"))
+			require.NoError(t, err)
+			require.Len(t, event.Threads[0].Frames, 1)
+
+			frame := event.Threads[0].Frames[0]
+			assert.Equal(t, c.Frame.Package, frame.Package, "Package")
+			assert.Equal(t, c.Frame.Receiver, frame.Receiver, "Receiver")
+			assert.Equal(t, c.Frame.Pointer, frame.Pointer, "Pointer")
+			assert.Equal(t, c.Frame.Func, frame.Func, "Func")
+			assert.Equal(t, c.Frame.TypeParams, frame.TypeParams, "TypeParams")
+			assert.Equal(t, c.Frame.ClosurePath, frame.ClosurePath, "ClosurePath")
+			assert.Equal(t, c.Frame.MethodValue, frame.MethodValue, "MethodValue")
+			assert.Equal(t, c.Name, frame.Name())
+		})
+	}
+}
+
+func TestNonFrameLines(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(`panic: oh no
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d
+exit status 2`))
+	require.NoError(t, err)
+	assert.Len(t, event.Threads[0].Frames, 1)
+}
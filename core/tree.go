@@ -0,0 +1,63 @@
+package core
+
+// GoroutineNode is a goroutine in the tree of which goroutine created which.
+type GoroutineNode struct {
+	Goroutine *Goroutine
+	Parent    *GoroutineNode
+	Children  []*GoroutineNode
+}
+
+// Tree links the goroutines in the event by their creator and returns the
+// roots, in dump order. Goroutines whose creator is not in the dump, because
+// it had already exited or Go did not report it, are roots, as are the stacks
+// of aggregated profiles, which have no IDs.
+func (e *Event) Tree() []*GoroutineNode {
+	nodes := make(map[*Goroutine]*GoroutineNode, len(e.Threads))
+	byID := make(map[string]*GoroutineNode, len(e.Threads))
+	for _, g := range e.Threads {
+		nodes[g] = &GoroutineNode{Goroutine: g}
+		if g.ID != "" {
+			byID[g.ID] = nodes[g]
+		}
+	}
+
+	roots := []*GoroutineNode{}
+	for _, g := range e.Threads {
+		node := nodes[g]
+
+		parent, ok := byID[g.ParentID]
+		if !ok || parent == node {
+			roots = append(roots, node)
+			continue
+		}
+
+		node.Parent = parent
+		parent.Children = append(parent.Children, node)
+	}
+
+	return roots
+}
+
+// Lineage returns the goroutine with the given ID followed by each of its
+// creators in turn, as far back as the dump allows. It returns nil if there
+// is no such goroutine.
+func (e *Event) Lineage(id string) []*Goroutine {
+	goroutines := make(map[string]*Goroutine, len(e.Threads))
+	for _, g := range e.Threads {
+		if g.ID != "" {
+			goroutines[g.ID] = g
+		}
+	}
+
+	lineage := []*Goroutine{}
+	seen := map[string]bool{}
+	for g := goroutines[id]; g != nil && !seen[g.ID]; g = goroutines[g.ParentID] {
+		seen[g.ID] = true
+		lineage = append(lineage, g)
+	}
+
+	if len(lineage) == 0 {
+		return nil
+	}
+	return lineage
+}
@@ -0,0 +1,172 @@
+package core_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/avos-io/panic-parse/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const createdByDump = `panic: oh no
+
+goroutine 58 [running]:
+main.worker()
+	/app/cmd/server/main.go:158 +0x10e
+created by main.handleRequest in goroutine 20
+	/app/cmd/server/main.go:120 +0x44c
+
+goroutine 1 [select]:
+main.main()
+	/app/cmd/server/main.go:30 +0x1d
+
+goroutine 20 [chan receive]:
+main.handleRequest()
+	/app/cmd/server/main.go:118 +0x2d
+created by main.serve in goroutine 1
+	/app/cmd/server/main.go:60 +0x3d
+
+goroutine 21 [IO wait]:
+main.poll()
+	/app/cmd/server/main.go:80 +0x4d
+created by main.serve in goroutine 1
+	/app/cmd/server/main.go:61 +0x5d
+
+goroutine 9 [select]:
+main.janitor()
+	/app/cmd/server/main.go:90 +0x6d
+created by main.init.0 in goroutine 3
+	/app/cmd/server/main.go:95 +0x7d`
+
+func TestCreatedBy(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(createdByDump))
+	require.NoError(t, err)
+
+	g := event.Threads[0]
+	require.Len(t, g.Frames, 1)
+	require.NotNil(t, g.CreatedBy)
+	assert.Equal(t, "main", g.CreatedBy.Package)
+	assert.Equal(t, "handleRequest", g.CreatedBy.Func)
+	assert.Equal(t, "/app/cmd/server/main.go", g.CreatedBy.File)
+	assert.Equal(t, 120, g.CreatedBy.Line)
+	assert.Equal(t, "20", g.ParentID)
+
+	assert.Nil(t, event.Threads[1].CreatedBy)
+	assert.Equal(t, "", event.Threads[1].ParentID)
+}
+
+func TestCreatedByBeforeGo121(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(`panic: oh no
+
+goroutine 2 [running]:
+main.anotherFunction()
+	/path/to/main.go:20
+created by main.main
+	/path/to/main.go:25`))
+	require.NoError(t, err)
+
+	g := event.Threads[0]
+	require.NotNil(t, g.CreatedBy)
+	assert.Equal(t, "main", g.CreatedBy.Func)
+	assert.Equal(t, "", g.ParentID)
+}
+
+func TestTree(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(createdByDump))
+	require.NoError(t, err)
+
+	roots := event.Tree()
+	require.Len(t, roots, 2)
+
+	main := roots[0]
+	assert.Equal(t, "1", main.Goroutine.ID)
+	assert.Nil(t, main.Parent)
+	require.Len(t, main.Children, 2)
+	assert.Equal(t, "20", main.Children[0].Goroutine.ID)
+	assert.Equal(t, "21", main.Children[1].Goroutine.ID)
+
+	handler := main.Children[0]
+	require.Len(t, handler.Children, 1)
+	assert.Equal(t, "58", handler.Children[0].Goroutine.ID)
+	assert.Same(t, handler, handler.Children[0].Parent)
+
+	// The creator of goroutine 9 has exited
+	assert.Equal(t, "9", roots[1].Goroutine.ID)
+}
+
+func TestLineage(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(createdByDump))
+	require.NoError(t, err)
+
+	ids := []string{}
+	for _, g := range event.Lineage(event.Panic.ThreadId) {
+		ids = append(ids, g.ID)
+	}
+	assert.Equal(t, []string{"58", "20", "1"}, ids)
+
+	assert.Nil(t, event.Lineage("404"))
+}
+
+func TestTreeAggregatedProfile(t *testing.T) {
+	event, err := core.ParseProfile(strings.NewReader(aggregatedProfile))
+	require.NoError(t, err)
+	require.Len(t, event.Threads, 2)
+
+	// Aggregated stacks have no IDs to link, so each is a root of its own
+	roots := event.Tree()
+	require.Len(t, roots, 2)
+	for i, root := range roots {
+		assert.Same(t, event.Threads[i], root.Goroutine)
+		assert.Nil(t, root.Parent)
+		assert.Empty(t, root.Children)
+	}
+
+	assert.Nil(t, event.Lineage(""))
+}
+
+func TestAncestors(t *testing.T) {
+	event, err := core.ParseEvent(strings.NewReader(`panic: oh no
+
+goroutine 18 [running]:
+main.leaf()
+	/app/main.go:30 +0x1d
+created by main.middle in goroutine 17
+	/app/main.go:25 +0x2d
+[originating from goroutine 17]:
+main.middle(...)
+	/app/main.go:25 +0x3d
+created by main.main
+	/app/main.go:20 +0x4d
+[originating from goroutine 1]:
+main.main(...)
+	/app/main.go:20 +0x5d
+
+goroutine 1 [chan receive]:
+main.main()
+	/app/main.go:21 +0x6d`))
+	require.NoError(t, err)
+	require.Len(t, event.Threads, 2)
+
+	g := event.Threads[0]
+	assert.Equal(t, "17", g.ParentID)
+	require.Len(t, g.Frames, 1)
+	require.Len(t, g.Ancestors, 2)
+
+	middle := g.Ancestors[0]
+	assert.Equal(t, "17", middle.ID)
+	assert.Equal(t, "1", middle.ParentID)
+	require.Len(t, middle.Frames, 1)
+	assert.Equal(t, "middle", middle.Frames[0].Func)
+	assert.Equal(t, 25, middle.Frames[0].Line)
+	require.NotNil(t, middle.CreatedBy)
+	assert.Equal(t, "main", middle.CreatedBy.Func)
+
+	main := g.Ancestors[1]
+	assert.Equal(t, "1", main.ID)
+	assert.Equal(t, "", main.ParentID)
+	require.Len(t, main.Frames, 1)
+	assert.Nil(t, main.CreatedBy)
+
+	assert.Equal(t, "chan receive", event.Threads[1].State)
+}
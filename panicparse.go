@@ -0,0 +1,54 @@
+// Package panicparse keeps the entry points of earlier versions, which parsed
+// panic logs straight into Sentry events. The parser now lives in the core
+// package and the Sentry conversion in the sentry package.
+package panicparse
+
+import (
+	"io"
+
+	"github.com/avos-io/panic-parse/core"
+	panicsentry "github.com/avos-io/panic-parse/sentry"
+	"github.com/getsentry/sentry-go"
+)
+
+type (
+	Panic     = core.Panic
+	Goroutine = core.Goroutine
+	Frame     = core.Frame
+)
+
+// ErrNoPanic is returned by ParseEvent when the input contains no panic or
+// fatal error header.
+var ErrNoPanic = core.ErrNoPanic
+
+// Event is a parsed panic that can still be converted to a Sentry event.
+type Event struct {
+	*core.Event
+}
+
+// Parse parses a panic log into a Sentry event. It returns nil if the log
+// cannot be parsed.
+//
+// Deprecated: use sentry.Parse.
+func Parse(trace io.Reader) *sentry.Event {
+	return panicsentry.Parse(trace)
+}
+
+// ParseEvent parses a panic log into an Event.
+//
+// Deprecated: use core.ParseEvent, and sentry.FromEvent to convert the event.
+func ParseEvent(trace io.Reader) (*Event, error) {
+	event, err := core.ParseEvent(trace)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Event{event}, nil
+}
+
+// ToSentry converts the event into a Sentry event.
+//
+// Deprecated: use sentry.FromEvent.
+func (e *Event) ToSentry() *sentry.Event {
+	return panicsentry.FromEvent(e.Event)
+}
@@ -0,0 +1,47 @@
+package panicparse_test
+
+import (
+	"strings"
+	"testing"
+
+	panicparse "github.com/avos-io/panic-parse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const trace = `panic: oh no
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d
+exit status 2
+`
+
+func TestParse(t *testing.T) {
+	event := panicparse.Parse(strings.NewReader(trace))
+	require.NotNil(t, event)
+	require.Len(t, event.Exception, 1)
+	assert.Equal(t, "oh no", event.Exception[0].Type)
+}
+
+func TestParseEventToSentry(t *testing.T) {
+	event, err := panicparse.ParseEvent(strings.NewReader(trace))
+	require.NoError(t, err)
+	assert.Equal(t, "oh no", event.Panic.Type)
+	require.Len(t, event.Threads, 1)
+
+	var frame *panicparse.Frame = event.Threads[0].Frames[0]
+	assert.Equal(t, "main", frame.Func)
+
+	converted := event.ToSentry()
+	require.Len(t, converted.Threads, 1)
+	assert.Equal(t, "1", converted.Threads[0].ID)
+}
+
+func TestParseEventNoPanic(t *testing.T) {
+	event, err := panicparse.ParseEvent(strings.NewReader("all good\n"))
+	assert.ErrorIs(t, err, panicparse.ErrNoPanic)
+	assert.Nil(t, event)
+
+	assert.Nil(t, panicparse.Parse(strings.NewReader("all good\n")))
+}
@@ -0,0 +1,25 @@
+package sentry
+
+// Option configures how events are converted to Sentry events.
+type Option func(*options)
+
+type options struct {
+	frameVars bool
+}
+
+// WithFrameVars includes each frame's arguments as Sentry frame vars, named
+// arg0, arg1 and so on and formatted as Go printed them. They are raw words
+// rather than typed values, so they are off by default.
+func WithFrameVars() Option {
+	return func(o *options) {
+		o.frameVars = true
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
@@ -0,0 +1,328 @@
+// Package sentry converts parsed panics into Sentry events.
+package sentry
+
+import (
+	"fmt"
+	"go/build"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/avos-io/panic-parse/core"
+	"github.com/getsentry/sentry-go"
+)
+
+// Parse parses a panic log into a Sentry event. It returns nil if the log
+// cannot be parsed.
+func Parse(trace io.Reader, opts ...Option) *sentry.Event {
+	event, err := core.ParseEvent(trace)
+	if err != nil {
+		return nil
+	}
+
+	return FromEvent(event, opts...)
+}
+
+// ParseGoroutines parses a goroutine dump into a Sentry event, accepting dumps
+// with no panic header as core.ParseGoroutines does. It returns nil if the
+// dump cannot be parsed.
+func ParseGoroutines(dump io.Reader, opts ...Option) *sentry.Event {
+	event, err := core.ParseGoroutines(dump)
+	if err != nil {
+		return nil
+	}
+
+	return FromEvent(event, opts...)
+}
+
+// FromEvent converts a parsed event into a Sentry event.
+func FromEvent(e *core.Event, opts ...Option) *sentry.Event {
+	return eventToSentryEvent(e, newOptions(opts))
+}
+
+func eventToSentryEvent(e *core.Event, o *options) *sentry.Event {
+	event := sentry.NewEvent()
+	event.Message = e.Panic.Description
+	event.Level = sentry.Level(e.Level)
+
+	// Sentry expects the most recent exception last, which is the order Go
+	// prints the chain in
+	event.Exception = make([]sentry.Exception, len(e.Chain))
+	for i, p := range e.Chain {
+		exception := panicToSentryException(p)
+		if p != e.Panic {
+			exception.ThreadID = event.Exception[0].ThreadID
+		}
+
+		if len(e.Chain) > 1 {
+			exception.Mechanism.ExceptionID = i
+			if i > 0 {
+				exception.Mechanism.ParentID = sentry.Pointer(i - 1)
+			}
+		}
+
+		event.Exception[i] = *exception
+	}
+
+	// Crashes in C code are triaged separately from pure Go ones
+	for _, p := range e.Chain {
+		if p.InCgo {
+			event.Tags["cgo"] = "true"
+		}
+	}
+
+	event.Threads = goroutinesToSentryThreads(e.Threads, o)
+
+	// A runtime stack means the runtime itself crashed, on the system stack,
+	// while running the goroutine that is reported first
+	if e.SystemStack != nil {
+		event.Threads = append([]sentry.Thread{{
+			ID:         "runtime",
+			Name:       "runtime stack",
+			Stacktrace: goroutineToSentryStacktrace(e.SystemStack, o),
+			Crashed:    true,
+		}}, event.Threads...)
+	}
+
+	// sentry-go has no registers field on stack traces, so they are sent as
+	// a context on the event instead
+	if len(e.Panic.Registers) > 0 {
+		registers := make(sentry.Context, len(e.Panic.Registers))
+		for name, value := range e.Panic.Registers {
+			registers[name] = fmt.Sprintf("%#x", value)
+		}
+		event.Contexts["registers"] = registers
+	}
+
+	if p := e.Preamble; p != nil {
+		preamble := sentry.Context{
+			"lines": p.Lines,
+		}
+		for name, value := range map[string]int64{
+			"stack_limit":     p.StackLimit,
+			"alloc_requested": p.AllocRequested,
+			"alloc_in_use":    p.AllocInUse,
+			"thread_limit":    p.ThreadLimit,
+		} {
+			if value != 0 {
+				preamble[name] = value
+			}
+		}
+		event.Contexts["preamble"] = preamble
+	}
+
+	if len(e.Diagnostics) > 0 {
+		diagnostics := make([]string, len(e.Diagnostics))
+		for i, d := range e.Diagnostics {
+			diagnostics[i] = d.Error()
+		}
+		event.Extra["parse_diagnostics"] = diagnostics
+	}
+
+	return event
+}
+
+// fatalMechanisms maps the kinds of fatal errors to Sentry mechanism types.
+var fatalMechanisms = map[core.FatalKind]string{
+	core.FatalConcurrentMap:  "data_race",
+	core.FatalDeadlock:       "deadlock",
+	core.FatalStackOverflow:  "stack_overflow",
+	core.FatalOutOfMemory:    "oom",
+	core.FatalUnlockUnlocked: "mutex_misuse",
+	core.FatalBadPointer:     "heap_corruption",
+}
+
+func panicToSentryException(p *core.Panic) *sentry.Exception {
+	mechanism := &sentry.Mechanism{
+		Type: "panic",
+		Data: make(map[string]interface{}),
+	}
+
+	if p.Recovered {
+		mechanism.Data["recovered"] = true
+	}
+	if p.Repanicked {
+		mechanism.Data["repanicked"] = true
+	}
+
+	if p.Signal != "" {
+		handled := false
+
+		mechanism.Type = "signal"
+		mechanism.Data["signal"] = p.Signal
+		mechanism.Data["code"] = p.Code
+		mechanism.Description = p.SignalInfo
+		mechanism.Handled = &handled
+		if p.Address != "" {
+			mechanism.Data["relevant_address"] = p.Address
+		}
+		if p.PC != "" {
+			mechanism.Data["program_counter"] = p.PC
+		}
+	}
+
+	if p.FaultAddress != "" {
+		mechanism.Data["fault_address"] = p.FaultAddress
+	}
+	if p.InCgo {
+		mechanism.Data["cgo"] = true
+	}
+
+	if mechanismType, ok := fatalMechanisms[p.FatalKind]; ok {
+		handled := false
+
+		mechanism.Type = mechanismType
+		mechanism.Handled = &handled
+	}
+
+	// Hang dumps are snapshots of a stuck process rather than crashes
+	if p.Type == core.HangDump {
+		handled := true
+
+		mechanism.Type = "hang_dump"
+		mechanism.Handled = &handled
+	}
+
+	threadId, err := strconv.ParseUint(p.ThreadId, 10, 64)
+	if err != nil {
+		threadId = 0
+	}
+
+	exception := &sentry.Exception{
+		Type:      p.Type,
+		Value:     p.Description,
+		ThreadID:  threadId,
+		Mechanism: mechanism,
+	}
+
+	// Runtime errors are grouped by their kind rather than all together as
+	// "runtime error"
+	if r := p.Runtime; r != nil {
+		exception.Type = string(r.Kind)
+		exception.Value = r.Message
+
+		for name, value := range map[string]int{"index": r.Index, "length": r.Length, "capacity": r.Capacity} {
+			if value >= 0 {
+				mechanism.Data[name] = value
+			}
+		}
+		for name, value := range map[string]string{
+			"expr": r.Expr, "interface": r.Interface, "concrete": r.Concrete,
+			"expected": r.Expected, "missing": r.Missing,
+		} {
+			if value != "" {
+				mechanism.Data[name] = value
+			}
+		}
+	}
+
+	return exception
+}
+
+func goroutinesToSentryThreads(threads []*core.Goroutine, o *options) []sentry.Thread {
+	sentryThreads := make([]sentry.Thread, len(threads))
+
+	for i, thread := range threads {
+		sentryThreads[i] = sentry.Thread{
+			ID:         thread.ID,
+			Name:       goroutineName(thread),
+			Stacktrace: goroutineToSentryStacktrace(thread, o),
+			Crashed:    thread.Crashed,
+		}
+	}
+
+	return sentryThreads
+}
+
+func goroutineToSentryStacktrace(thread *core.Goroutine, o *options) *sentry.Stacktrace {
+	frames := stackFrames(thread)
+	numFrames := len(frames)
+
+	stacktrace := &sentry.Stacktrace{
+		Frames: make([]sentry.Frame, numFrames),
+	}
+
+	// Frames omitted are given as the [start, end) indices they would
+	// have had in the full stack. Sentry counts from the outermost
+	// frame, which comes after the elided frames in Go's order.
+	if thread.FramesElided && thread.ElidedCount > 0 {
+		start := uint(numFrames - thread.ElidedAt)
+		stacktrace.FramesOmitted = []uint{start, start + uint(thread.ElidedCount)}
+	}
+
+	for j, f := range frames {
+		inApp := (f.File != "" || f.Package != "") && !(strings.HasPrefix(f.File, build.Default.GOROOT) ||
+			strings.Contains(f.File, "go/pkg/mod") ||
+			strings.Contains(f.Package, "vendor") ||
+			strings.Contains(f.Package, "third_party"))
+
+		// Sentry expects the frames in reverse order
+		stacktrace.Frames[numFrames-j-1] = sentry.Frame{
+			Package:  f.Package,
+			Function: f.Name(),
+			Filename: f.File,
+			Lineno:   f.Line,
+			InApp:    inApp,
+		}
+
+		if f.PC != 0 {
+			stacktrace.Frames[numFrames-j-1].InstructionAddr = fmt.Sprintf("%#x", f.PC)
+		}
+
+		// C frames are symbolicated from their address like other native
+		// platforms' frames
+		if f.Native {
+			stacktrace.Frames[numFrames-j-1].Platform = "native"
+			stacktrace.Frames[numFrames-j-1].Package = f.Module
+			stacktrace.Frames[numFrames-j-1].Symbol = f.Symbol
+		}
+
+		vars := map[string]interface{}{}
+		if f.Inlined {
+			vars["inlined"] = true
+		} else if o.frameVars {
+			for k, arg := range f.Arguments {
+				vars[fmt.Sprintf("arg%d", k)] = arg.String()
+			}
+		}
+		if len(vars) > 0 {
+			stacktrace.Frames[numFrames-j-1].Vars = vars
+		}
+	}
+
+	return stacktrace
+}
+
+// stackFrames returns a goroutine's frames, innermost first, followed by the
+// go statement that created it and then the stacks of any recorded ancestors,
+// so that Sentry shows the full causal history as one stack.
+func stackFrames(g *core.Goroutine) []*core.Frame {
+	frames := []*core.Frame{}
+	for _, stack := range append([]*core.Goroutine{g}, g.Ancestors...) {
+		frames = append(frames, stack.Frames...)
+		if stack.CreatedBy != nil {
+			frames = append(frames, stack.CreatedBy)
+		}
+	}
+	return frames
+}
+
+// goroutineName names a thread after the goroutine header Go printed, so the
+// wait duration and thread lock are visible in Sentry.
+func goroutineName(g *core.Goroutine) string {
+	// Stacks in aggregated profiles stand for several goroutines
+	if g.Count > 0 {
+		return fmt.Sprintf("%d goroutines", g.Count)
+	}
+
+	status := []string{g.State}
+	if g.WaitDuration > 0 {
+		status = append(status, fmt.Sprintf("%d minutes", int(g.WaitDuration/time.Minute)))
+	}
+	if g.LockedToThread {
+		status = append(status, "locked to thread")
+	}
+
+	return fmt.Sprintf("goroutine %s [%s]", g.ID, strings.Join(status, ", "))
+}
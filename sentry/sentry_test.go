@@ -1,10 +1,12 @@
-package panicparse_test
+package sentry_test
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
-	panicparse "github.com/avos-io/panic-parse"
+	"github.com/avos-io/panic-parse/core"
+	panicsentry "github.com/avos-io/panic-parse/sentry"
 	"github.com/getsentry/sentry-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -14,7 +16,7 @@ func TestPanicParse(t *testing.T) {
 	for name, tc := range testCases {
 		c := tc
 		t.Run(name, func(t *testing.T) {
-			event := panicparse.Parse(strings.NewReader(c.Data))
+			event := panicsentry.Parse(strings.NewReader(c.Data))
 
 			compareEvents(t, c.Result, event)
 		})
@@ -37,7 +39,7 @@ main.main()
 		Result: &sentry.Event{
 			Message: "invalid memory address or nil pointer dereference",
 			Exception: []sentry.Exception{{
-				Type:     "runtime error",
+				Type:     "nil pointer dereference",
 				Value:    "invalid memory address or nil pointer dereference",
 				ThreadID: 1,
 				Mechanism: &sentry.Mechanism{
@@ -302,7 +304,7 @@ main.aFunction()
 		Result: &sentry.Event{
 			Message: "invalid memory address or nil pointer dereference",
 			Exception: []sentry.Exception{{
-				Type:     "runtime error",
+				Type:     "nil pointer dereference",
 				Value:    "invalid memory address or nil pointer dereference",
 				ThreadID: 1,
 				Mechanism: &sentry.Mechanism{
@@ -367,11 +369,12 @@ runtime.mstart()
 					Stacktrace: &sentry.Stacktrace{
 						Frames: []sentry.Frame{
 							{
-								Package:  "runtime",
-								Function: "throw",
-								Filename: "/usr/local/go/src/runtime/panic.go",
-								Lineno:   1116,
-								InApp:    false,
+								Package:         "runtime",
+								Function:        "throw",
+								Filename:        "/usr/local/go/src/runtime/panic.go",
+								Lineno:          1116,
+								InApp:           false,
+								InstructionAddr: "0x40c2e2",
 							},
 						},
 					},
@@ -381,25 +384,28 @@ runtime.mstart()
 					Stacktrace: &sentry.Stacktrace{
 						Frames: []sentry.Frame{
 							{
-								Package:  "runtime",
-								Function: "mstart",
-								Filename: "/usr/local/go/src/runtime/proc.go",
-								Lineno:   1187,
-								InApp:    false,
+								Package:         "runtime",
+								Function:        "mstart",
+								Filename:        "/usr/local/go/src/runtime/proc.go",
+								Lineno:          1187,
+								InApp:           false,
+								InstructionAddr: "0x42c1c0",
 							},
 							{
-								Package:  "runtime",
-								Function: "mstart1",
-								Filename: "/usr/local/go/src/runtime/proc.go",
-								Lineno:   1231,
-								InApp:    false,
+								Package:         "runtime",
+								Function:        "mstart1",
+								Filename:        "/usr/local/go/src/runtime/proc.go",
+								Lineno:          1231,
+								InApp:           false,
+								InstructionAddr: "0x42c3e1",
 							},
 							{
-								Package:  "runtime",
-								Function: "systemstack_switch",
-								Filename: "/usr/local/go/src/runtime/asm_amd64.s",
-								Lineno:   351,
-								InApp:    false,
+								Package:         "runtime",
+								Function:        "systemstack_switch",
+								Filename:        "/usr/local/go/src/runtime/asm_amd64.s",
+								Lineno:          351,
+								InApp:           false,
+								InstructionAddr: "0x45a1a0",
 							},
 						},
 					},
@@ -438,13 +444,13 @@ created by main.mainInner in goroutine 1
 			Level: sentry.LevelFatal,
 			Threads: []sentry.Thread{
 				{
-					Name: "foo",
+					Name: "goroutine 58 [running]",
 					ID:   "58",
 					Stacktrace: &sentry.Stacktrace{
 						Frames: []sentry.Frame{
 							{
 								Package:  "main",
-								Function: "mainInner in goroutine 1",
+								Function: "mainInner",
 								Filename: "/app/cmd/server/main.go",
 								Lineno:   520,
 								InApp:    true,
@@ -469,6 +475,7 @@ created by main.mainInner in goroutine 1
 								Filename: "/go/pkg/mod/github.com/rs/zerolog@v1.32.0/event.go",
 								Lineno:   110,
 								InApp:    false,
+								Vars:     map[string]interface{}{"inlined": true},
 							},
 							{
 								Package:  "github.com/rs/zerolog",
@@ -490,6 +497,56 @@ created by main.mainInner in goroutine 1
 			},
 		},
 	},
+	"chained panic": {
+		Data: `panic: runtime error: invalid memory address or nil pointer dereference [recovered]
+	panic: cleanup failed
+[signal SIGSEGV: segmentation violation code=0x1 addr=0x0 pc=0x20314]
+
+goroutine 1 [running]:
+main.main()
+	/tmp/sandbox675251439/main.go:23 +0x314`,
+		Result: &sentry.Event{
+			Message: "invalid memory address or nil pointer dereference",
+			Exception: []sentry.Exception{
+				{
+					Type:     "nil pointer dereference",
+					Value:    "invalid memory address or nil pointer dereference",
+					ThreadID: 1,
+					Mechanism: &sentry.Mechanism{
+						Type:        "signal",
+						Data:        map[string]interface{}{"signal": "SIGSEGV", "code": "0x1", "relevant_address": "0x0", "program_counter": "0x20314", "recovered": true},
+						Description: "segmentation violation",
+						Handled:     new(bool),
+					},
+				},
+				{
+					Type:     "cleanup failed",
+					ThreadID: 1,
+					Mechanism: &sentry.Mechanism{
+						Type:        "panic",
+						Data:        map[string]interface{}{},
+						ExceptionID: 1,
+						ParentID:    sentry.Pointer(0),
+					},
+				},
+			},
+			Threads: []sentry.Thread{{
+				ID: "1",
+				Stacktrace: &sentry.Stacktrace{
+					Frames: []sentry.Frame{
+						{
+							Package:  "main",
+							Function: "main",
+							Filename: "/tmp/sandbox675251439/main.go",
+							Lineno:   23,
+							InApp:    true,
+						},
+					},
+				},
+			}},
+			Level: "fatal",
+		},
+	},
 
 	// Invalid input cases
 	"empty": {
@@ -508,7 +565,7 @@ main.main()
 		Result: &sentry.Event{
 			Message: "invalid memory address or nil pointer dereference",
 			Exception: []sentry.Exception{{
-				Type:  "runtime error",
+				Type:  "nil pointer dereference",
 				Value: "invalid memory address or nil pointer dereference",
 				Mechanism: &sentry.Mechanism{
 					Type:        "signal",
@@ -529,7 +586,7 @@ goroutine 1 [running]:`,
 		Result: &sentry.Event{
 			Message: "invalid memory address or nil pointer dereference",
 			Exception: []sentry.Exception{{
-				Type:     "runtime error",
+				Type:     "nil pointer dereference",
 				Value:    "invalid memory address or nil pointer dereference",
 				ThreadID: 1,
 				Mechanism: &sentry.Mechanism{
@@ -581,6 +638,337 @@ panic(0x112c00, 0x1040a038)`,
 	},
 }
 
+func TestThreadNames(t *testing.T) {
+	event := panicsentry.Parse(strings.NewReader(`panic: oh no
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d
+
+goroutine 6 [syscall, 125 minutes, locked to thread]:
+main.poller()
+	/app/main.go:30 +0x3d`))
+	require.NotNil(t, event)
+	require.Len(t, event.Threads, 2)
+
+	assert.Equal(t, "goroutine 1 [running]", event.Threads[0].Name)
+	assert.Equal(t, "goroutine 6 [syscall, 125 minutes, locked to thread]", event.Threads[1].Name)
+}
+
+func TestAncestorFrames(t *testing.T) {
+	event := panicsentry.Parse(strings.NewReader(`panic: oh no
+
+goroutine 18 [running]:
+main.leaf()
+	/app/main.go:30 +0x1d
+created by main.middle in goroutine 17
+	/app/main.go:25 +0x2d
+[originating from goroutine 17]:
+main.middle(...)
+	/app/main.go:24 +0x3d
+created by main.main
+	/app/main.go:20 +0x4d`))
+	require.NotNil(t, event)
+	require.Len(t, event.Threads, 1)
+
+	functions := []string{}
+	for _, f := range event.Threads[0].Stacktrace.Frames {
+		functions = append(functions, fmt.Sprintf("%s:%d", f.Function, f.Lineno))
+	}
+	assert.Equal(t, []string{"main:20", "middle:24", "middle:25", "leaf:30"}, functions)
+}
+
+func TestFrameVars(t *testing.T) {
+	data := `panic: oh no
+
+goroutine 1 [running]:
+main.handle(0x58?, {0x1419348, 0xc0004924b0}, ...)
+	/app/main.go:10 +0x1d
+main.main()
+	/app/main.go:20 +0x2d`
+
+	event := panicsentry.Parse(strings.NewReader(data))
+	require.NotNil(t, event)
+	for _, f := range event.Threads[0].Stacktrace.Frames {
+		assert.Nil(t, f.Vars)
+	}
+
+	event = panicsentry.Parse(strings.NewReader(data), panicsentry.WithFrameVars())
+	require.NotNil(t, event)
+	frames := event.Threads[0].Stacktrace.Frames
+	require.Len(t, frames, 2)
+	assert.Nil(t, frames[0].Vars)
+	assert.Equal(t, map[string]interface{}{
+		"arg0": "0x58?",
+		"arg1": "{0x1419348, 0xc0004924b0}",
+		"arg2": "...",
+	}, frames[1].Vars)
+}
+
+func TestFramesOmitted(t *testing.T) {
+	event := panicsentry.Parse(strings.NewReader(`panic: oh no
+
+goroutine 1 [running]:
+main.recurse(0x0)
+	/app/main.go:10 +0x1d
+main.recurse(0x1)
+	/app/main.go:12 +0x2d
+...12 frames elided...
+main.recurse(0xe)
+	/app/main.go:12 +0x2d
+created by main.main in goroutine 1
+	/app/main.go:20 +0x3d`))
+	require.NotNil(t, event)
+
+	stacktrace := event.Threads[0].Stacktrace
+	require.Len(t, stacktrace.Frames, 4)
+	assert.Equal(t, []uint{2, 14}, stacktrace.FramesOmitted)
+
+	event = panicsentry.Parse(strings.NewReader(testCases["frames elided"].Data))
+	require.NotNil(t, event)
+	assert.Nil(t, event.Threads[0].Stacktrace.FramesOmitted, "count unknown")
+}
+
+func TestRegisters(t *testing.T) {
+	event := panicsentry.Parse(strings.NewReader(`panic: oh no
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d
+
+rax    0x0
+rip    0x46f2a1
+rflags 0x246`))
+	require.NotNil(t, event)
+
+	assert.Equal(t, sentry.Context{
+		"rax":    "0x0",
+		"rip":    "0x46f2a1",
+		"rflags": "0x246",
+	}, event.Contexts["registers"])
+}
+
+func TestSystemStack(t *testing.T) {
+	event := panicsentry.Parse(strings.NewReader(`fatal error: unexpected signal during runtime execution
+[signal SIGSEGV: segmentation violation code=0x1 addr=0x0 pc=0x4541e2]
+
+runtime stack:
+runtime.throw({0x4a1d2b?, 0x0?})
+	/usr/local/go/src/runtime/panic.go:1047 +0x5d fp=0x7ffc5e0b6f00 sp=0x7ffc5e0b6ed0 pc=0x43401d
+runtime.sigpanic()
+	/usr/local/go/src/runtime/signal_unix.go:819 +0x3e9 fp=0x7ffc5e0b6f60 sp=0x7ffc5e0b6f00 pc=0x448a09
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d`))
+	require.NotNil(t, event)
+
+	require.Len(t, event.Threads, 2)
+	assert.Equal(t, "runtime", event.Threads[0].ID)
+	assert.Equal(t, "runtime stack", event.Threads[0].Name)
+	assert.True(t, event.Threads[0].Crashed)
+	require.Len(t, event.Threads[0].Stacktrace.Frames, 2)
+	assert.Equal(t, "sigpanic", event.Threads[0].Stacktrace.Frames[0].Function)
+
+	assert.Equal(t, "1", event.Threads[1].ID)
+	assert.False(t, event.Threads[1].Crashed)
+}
+
+func TestCrashedThread(t *testing.T) {
+	event := panicsentry.Parse(strings.NewReader(`panic: oh no
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d
+
+goroutine 2 [select]:
+main.worker()
+	/app/main.go:20 +0x2d`))
+	require.NotNil(t, event)
+
+	require.Len(t, event.Threads, 2)
+	assert.True(t, event.Threads[0].Crashed)
+	assert.False(t, event.Threads[1].Crashed)
+}
+
+func TestHangDump(t *testing.T) {
+	event := panicsentry.ParseGoroutines(strings.NewReader(`goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d
+
+goroutine 7 [chan receive, 12 minutes]:
+main.worker()
+	/app/main.go:20 +0x2d`))
+	require.NotNil(t, event)
+
+	assert.Equal(t, sentry.LevelError, event.Level)
+	require.Len(t, event.Exception, 1)
+	assert.Equal(t, core.HangDump, event.Exception[0].Type)
+	assert.Equal(t, "hang_dump", event.Exception[0].Mechanism.Type)
+	require.NotNil(t, event.Exception[0].Mechanism.Handled)
+	assert.True(t, *event.Exception[0].Mechanism.Handled)
+	assert.Len(t, event.Threads, 2)
+}
+
+func TestRuntimeErrors(t *testing.T) {
+	event := panicsentry.Parse(strings.NewReader(`panic: runtime error: index out of range [5] with length 3
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d`))
+	require.NotNil(t, event)
+
+	require.Len(t, event.Exception, 1)
+	assert.Equal(t, "index out of range", event.Exception[0].Type)
+	assert.Equal(t, "index out of range [5] with length 3", event.Exception[0].Value)
+	assert.Equal(t, map[string]interface{}{
+		"index":  5,
+		"length": 3,
+		"expr":   "[5]",
+	}, event.Exception[0].Mechanism.Data)
+}
+
+func TestFatalErrors(t *testing.T) {
+	event := panicsentry.Parse(strings.NewReader(`fatal error: concurrent map writes
+
+goroutine 7 [running]:
+runtime.mapassign_fast64(0x4a3f20, 0xc00007a180, 0x1)
+	/usr/local/go/src/runtime/map_fast64.go:102 +0x2b6
+main.writer(0xc00007a180)
+	/app/main.go:12 +0x45
+
+goroutine 1 [sleep]:
+main.main()
+	/app/main.go:22 +0x8a
+
+goroutine 8 [runnable]:
+runtime.mapassign_fast64(0x4a3f20, 0xc00007a180, 0x2)
+	/usr/local/go/src/runtime/map_fast64.go:93 +0x1f6
+main.writer(0xc00007a180)
+	/app/main.go:12 +0x45`))
+	require.NotNil(t, event)
+
+	require.Len(t, event.Exception, 1)
+	assert.Equal(t, "data_race", event.Exception[0].Mechanism.Type)
+	require.NotNil(t, event.Exception[0].Mechanism.Handled)
+	assert.False(t, *event.Exception[0].Mechanism.Handled)
+
+	require.Len(t, event.Threads, 3)
+	assert.True(t, event.Threads[0].Crashed)
+	assert.False(t, event.Threads[1].Crashed)
+	assert.True(t, event.Threads[2].Crashed)
+
+	event = panicsentry.Parse(strings.NewReader(`fatal error: all goroutines are asleep - deadlock!
+
+goroutine 1 [chan receive]:
+main.main()
+	/app/main.go:10 +0x1d`))
+	require.NotNil(t, event)
+	assert.Equal(t, "deadlock", event.Exception[0].Mechanism.Type)
+}
+
+func TestPreamble(t *testing.T) {
+	event := panicsentry.Parse(strings.NewReader(`runtime: out of memory: cannot allocate 8192-byte block (3951132672 in use)
+fatal error: out of memory
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d`))
+	require.NotNil(t, event)
+
+	assert.Equal(t, sentry.Context{
+		"lines":           []string{"runtime: out of memory: cannot allocate 8192-byte block (3951132672 in use)"},
+		"alloc_requested": int64(8192),
+		"alloc_in_use":    int64(3951132672),
+	}, event.Contexts["preamble"])
+	assert.Equal(t, "oom", event.Exception[0].Mechanism.Type)
+}
+
+func TestCgo(t *testing.T) {
+	event := panicsentry.Parse(strings.NewReader(`unexpected fault address 0x7f1e2c000000
+fatal error: fault
+[signal SIGSEGV: segmentation violation code=0x2 addr=0x7f1e2c000000 pc=0x45e1b2]
+signal arrived during cgo execution
+
+goroutine 1 [syscall]:
+main.main()
+	/app/main.go:12 +0x17`))
+	require.NotNil(t, event)
+
+	assert.Equal(t, "true", event.Tags["cgo"])
+	require.Len(t, event.Exception, 1)
+	assert.Equal(t, "0x7f1e2c000000", event.Exception[0].Mechanism.Data["fault_address"])
+	assert.Equal(t, true, event.Exception[0].Mechanism.Data["cgo"])
+
+	event = panicsentry.Parse(strings.NewReader(`panic: oh no
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d`))
+	require.NotNil(t, event)
+	assert.NotContains(t, event.Tags, "cgo")
+}
+
+func TestNativeFrames(t *testing.T) {
+	event := panicsentry.Parse(strings.NewReader(`SIGSEGV: segmentation violation
+PC=0x7f5b6b5e5c4a m=0 sigcode=1 addr=0x0
+signal arrived during cgo execution
+
+goroutine 1 [syscall]:
+crash_in_c
+	/app/crash.c:5 pc=0x48061d
+libc.so.6+0x29d90
+	pc=0x7f5b6b429d90
+main._Cfunc_crash()
+	_cgo_gotypes.go:39 +0x45
+main.main()
+	/app/main.go:12 +0x17`))
+	require.NotNil(t, event)
+
+	frames := event.Threads[0].Stacktrace.Frames
+	require.Len(t, frames, 4)
+
+	assert.Empty(t, frames[0].Platform)
+	assert.Equal(t, "main", frames[0].Function)
+
+	assert.Equal(t, "native", frames[2].Platform)
+	assert.Equal(t, "libc.so.6", frames[2].Package)
+	assert.Equal(t, "0x7f5b6b429d90", frames[2].InstructionAddr)
+
+	assert.Equal(t, "native", frames[3].Platform)
+	assert.Equal(t, "crash_in_c", frames[3].Function)
+	assert.Equal(t, "crash_in_c", frames[3].Symbol)
+	assert.Equal(t, "/app/crash.c", frames[3].Filename)
+	assert.Equal(t, "0x48061d", frames[3].InstructionAddr)
+}
+
+func TestInlinedFrames(t *testing.T) {
+	event := panicsentry.Parse(strings.NewReader(`fatal error: oh no
+
+goroutine 1 [running]:
+runtime.throw({0x112c00, 0x1040a038})
+	/usr/local/go/src/runtime/panic.go:1116 +0x72 fp=0x7fffbf9f7f18 sp=0x7fffbf9f7f00 pc=0x40c2e2
+main.inlined(...)
+	/app/main.go:20
+main.main()
+	/app/main.go:30 +0x2d`))
+	require.NotNil(t, event)
+
+	frames := event.Threads[0].Stacktrace.Frames
+	require.Len(t, frames, 3)
+
+	assert.Equal(t, "main", frames[0].Function)
+	assert.Empty(t, frames[0].InstructionAddr)
+	assert.Nil(t, frames[0].Vars)
+
+	assert.Equal(t, "inlined", frames[1].Function)
+	assert.Equal(t, map[string]interface{}{"inlined": true}, frames[1].Vars)
+
+	assert.Equal(t, "throw", frames[2].Function)
+	assert.Equal(t, "0x40c2e2", frames[2].InstructionAddr)
+	assert.Nil(t, frames[2].Vars)
+}
+
 func compareEvents(t *testing.T, expected *sentry.Event, actual *sentry.Event) {
 	is := assert.New(t)
 
@@ -630,6 +1018,8 @@ func compareMechanisms(t *testing.T, expected *sentry.Mechanism, actual *sentry.
 	is.Equal(expected.Description, actual.Description, "Mechanism Description")
 	is.Equal(expected.Data, actual.Data, "Mechanism Data")
 	is.Equal(expected.Handled, actual.Handled, "Mechanism Handled")
+	is.Equal(expected.ExceptionID, actual.ExceptionID, "Mechanism ExceptionID")
+	is.Equal(expected.ParentID, actual.ParentID, "Mechanism ParentID")
 }
 
 func compareThreads(t *testing.T, expected *sentry.Thread, actual *sentry.Thread) {
@@ -639,6 +1029,9 @@ func compareThreads(t *testing.T, expected *sentry.Thread, actual *sentry.Thread
 	require.NotNil(t, actual)
 
 	is.Equal(expected.ID, actual.ID, "Thread ID")
+	if expected.Name != "" {
+		is.Equal(expected.Name, actual.Name, "Thread Name")
+	}
 
 	compareStacktrace(t, expected.Stacktrace, actual.Stacktrace)
 }
@@ -650,4 +1043,5 @@ func compareStacktrace(t *testing.T, expected *sentry.Stacktrace, actual *sentry
 	require.NotNil(t, actual)
 
 	is.Equal(expected.Frames, actual.Frames, "Stacktrace Frames")
+	is.Equal(expected.FramesOmitted, actual.FramesOmitted, "Stacktrace FramesOmitted")
 }
@@ -0,0 +1,67 @@
+package watch
+
+import "time"
+
+// Option configures a Watcher.
+type Option func(*options)
+
+type options struct {
+	interval      time.Duration
+	threshold     time.Duration
+	growthSamples int
+	onError       func(error)
+}
+
+// WithInterval sets how often Run polls the source. The default is a minute,
+// which is also used for intervals of zero or less.
+func WithInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.interval = d
+	}
+}
+
+// WithThreshold sets how long a goroutine must stay blocked in the same state
+// and stack before it is reported as stuck. The default is ten minutes.
+func WithThreshold(d time.Duration) Option {
+	return func(o *options) {
+		o.threshold = d
+	}
+}
+
+// WithGrowthSamples sets how many polls in a row the goroutine count must
+// grow before a leak is reported. The default is five; zero or less disables
+// leak detection.
+func WithGrowthSamples(n int) Option {
+	return func(o *options) {
+		o.growthSamples = n
+	}
+}
+
+// WithErrorHandler sets a handler for the errors Run meets fetching or
+// parsing dumps. Run keeps polling after an error; without a handler the
+// error is dropped.
+func WithErrorHandler(handler func(error)) Option {
+	return func(o *options) {
+		o.onError = handler
+	}
+}
+
+const defaultInterval = time.Minute
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		interval:      defaultInterval,
+		threshold:     10 * time.Minute,
+		growthSamples: 5,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	// time.NewTicker panics on intervals of zero or less
+	if o.interval <= 0 {
+		o.interval = defaultInterval
+	}
+
+	return o
+}
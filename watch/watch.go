@@ -0,0 +1,232 @@
+// Package watch polls a process's goroutine dumps and reports suspected hangs
+// and leaks, for processes that never actually panic.
+package watch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime/pprof"
+	"sync"
+	"time"
+
+	"github.com/avos-io/panic-parse/core"
+)
+
+// Stuck and Leak are the Types of the synthetic panics of the events a
+// Watcher reports.
+const (
+	Stuck = "stuck goroutines"
+	Leak  = "goroutine leak"
+)
+
+// Source returns the current goroutine dump of the watched process, in any
+// format core.ParseProfile accepts. The debug=2 format is needed to detect
+// stuck goroutines, as the debug=1 format has no goroutine IDs.
+type Source func(ctx context.Context) (io.ReadCloser, error)
+
+// URL returns a Source fetching dumps over HTTP, usually from the
+// /debug/pprof/goroutine?debug=2 endpoint of net/http/pprof.
+func URL(url string) Source {
+	return func(ctx context.Context) (io.ReadCloser, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("fetching %s: %s", url, resp.Status)
+		}
+
+		return resp.Body, nil
+	}
+}
+
+// Local returns a Source dumping the goroutines of the current process.
+func Local() Source {
+	return func(ctx context.Context) (io.ReadCloser, error) {
+		var buf bytes.Buffer
+		if err := pprof.Lookup("goroutine").WriteTo(&buf, 2); err != nil {
+			return nil, err
+		}
+		return io.NopCloser(&buf), nil
+	}
+}
+
+// Watcher compares successive goroutine dumps. It reports goroutines that
+// stay blocked in the same state and stack for longer than a threshold, and
+// goroutine counts that grow on every poll, by passing the handler an event
+// at level "warning". Each stuck goroutine is only reported once.
+type Watcher struct {
+	source  Source
+	handler func(*core.Event)
+	options *options
+
+	mu        sync.Mutex
+	firstSeen map[string]time.Time
+	reported  map[string]bool
+	counts    []int
+}
+
+// New returns a Watcher polling source and passing each suspected hang or
+// leak to handler.
+func New(source Source, handler func(*core.Event), opts ...Option) *Watcher {
+	return &Watcher{
+		source:    source,
+		handler:   handler,
+		options:   newOptions(opts),
+		firstSeen: map[string]time.Time{},
+		reported:  map[string]bool{},
+	}
+}
+
+// Run polls the source at the configured interval until ctx is done, and
+// returns ctx's error.
+func (w *Watcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.options.interval)
+	defer ticker.Stop()
+
+	for {
+		err := w.Poll(ctx)
+		if err != nil && ctx.Err() == nil && w.options.onError != nil {
+			w.options.onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetches and compares a single dump, calling the handler for anything
+// it finds.
+func (w *Watcher) Poll(ctx context.Context) error {
+	dump, err := w.source(ctx)
+	if err != nil {
+		return err
+	}
+	defer dump.Close()
+
+	snapshot, err := core.ParseProfile(dump)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	events := w.compare(snapshot, time.Now())
+	w.mu.Unlock()
+
+	for _, event := range events {
+		w.handler(event)
+	}
+
+	return nil
+}
+
+// compare records snapshot, taken at now, and returns the events it raises.
+func (w *Watcher) compare(snapshot *core.Event, now time.Time) []*core.Event {
+	events := []*core.Event{}
+
+	stuck := []*core.Goroutine{}
+	firstSeen := map[string]time.Time{}
+	reported := map[string]bool{}
+	count := 0
+
+	for _, g := range snapshot.Threads {
+		if g.Count > 0 {
+			count += g.Count
+		} else {
+			count++
+		}
+
+		// Aggregated stacks can't be followed from one dump to the next,
+		// and running goroutines aren't blocked
+		if g.ID == "" || g.State == "running" || g.State == "runnable" {
+			continue
+		}
+
+		key := g.ID + " [" + g.State + "]\n" + g.Signature()
+
+		first, ok := w.firstSeen[key]
+		if !ok {
+			first = now
+		}
+		firstSeen[key] = first
+
+		// The runtime's own wait time covers the time before the first
+		// dump, but is only printed in whole minutes
+		blocked := now.Sub(first)
+		if g.WaitDuration > blocked {
+			blocked = g.WaitDuration
+		}
+
+		if w.reported[key] {
+			reported[key] = true
+		} else if blocked >= w.options.threshold {
+			reported[key] = true
+			stuck = append(stuck, g)
+		}
+	}
+
+	w.firstSeen = firstSeen
+	w.reported = reported
+
+	if len(stuck) > 0 {
+		events = append(events, newEvent(Stuck,
+			fmt.Sprintf("%d goroutines blocked for at least %s", len(stuck), w.options.threshold),
+			stuck))
+	}
+
+	if samples := w.options.growthSamples; samples > 0 {
+		w.counts = append(w.counts, count)
+		if len(w.counts) > samples+1 {
+			w.counts = w.counts[len(w.counts)-samples-1:]
+		}
+
+		if len(w.counts) == samples+1 && increasing(w.counts) {
+			events = append(events, newEvent(Leak,
+				fmt.Sprintf("goroutine count grew from %d to %d over %d polls", w.counts[0], count, samples),
+				snapshot.Threads))
+
+			// Start counting again so that the same growth is only
+			// reported once
+			w.counts = w.counts[samples:]
+		}
+	}
+
+	return events
+}
+
+func newEvent(kind, description string, threads []*core.Goroutine) *core.Event {
+	panic := &core.Panic{
+		Type:        kind,
+		Description: description,
+		Synthetic:   true,
+	}
+
+	return &core.Event{
+		Panic:   panic,
+		Chain:   []*core.Panic{panic},
+		Threads: threads,
+		Level:   "warning",
+	}
+}
+
+func increasing(counts []int) bool {
+	for i := 1; i < len(counts); i++ {
+		if counts[i] <= counts[i-1] {
+			return false
+		}
+	}
+	return true
+}
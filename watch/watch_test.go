@@ -0,0 +1,176 @@
+package watch_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/avos-io/panic-parse/core"
+	"github.com/avos-io/panic-parse/watch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serve returns a server answering each request with the next dump, and the
+// last one once they run out.
+func serve(t *testing.T, dumps ...string) *httptest.Server {
+	var mu sync.Mutex
+	served := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		dump := dumps[len(dumps)-1]
+		if served < len(dumps) {
+			dump = dumps[served]
+		}
+		served++
+
+		fmt.Fprint(w, dump)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func workers(n int) string {
+	var b strings.Builder
+	b.WriteString("goroutine 1 [running]:\nmain.main()\n\t/app/main.go:10 +0x1d\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "\ngoroutine %d [select]:\nmain.worker()\n\t/app/main.go:20 +0x2d\n", i+2)
+	}
+	return b.String()
+}
+
+func TestWatcherStuck(t *testing.T) {
+	server := serve(t, `goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d
+
+goroutine 5 [chan receive, 15 minutes]:
+main.consumer()
+	/app/main.go:20 +0x2d
+
+goroutine 6 [select]:
+main.poller()
+	/app/main.go:30 +0x3d
+`)
+
+	events := []*core.Event{}
+	watcher := watch.New(watch.URL(server.URL+"/debug/pprof/goroutine?debug=2"), func(e *core.Event) {
+		events = append(events, e)
+	}, watch.WithGrowthSamples(0))
+
+	require.NoError(t, watcher.Poll(context.Background()))
+	require.NoError(t, watcher.Poll(context.Background()))
+
+	require.Len(t, events, 1)
+	assert.Equal(t, watch.Stuck, events[0].Panic.Type)
+	assert.Equal(t, "warning", events[0].Level)
+	require.Len(t, events[0].Threads, 1)
+	assert.Equal(t, "5", events[0].Threads[0].ID)
+}
+
+func TestWatcherStuckBetweenPolls(t *testing.T) {
+	server := serve(t, workers(1))
+
+	events := []*core.Event{}
+	watcher := watch.New(watch.URL(server.URL), func(e *core.Event) {
+		events = append(events, e)
+	}, watch.WithThreshold(20*time.Millisecond), watch.WithGrowthSamples(0))
+
+	require.NoError(t, watcher.Poll(context.Background()))
+	assert.Empty(t, events)
+
+	time.Sleep(30 * time.Millisecond)
+
+	require.NoError(t, watcher.Poll(context.Background()))
+	require.Len(t, events, 1)
+	require.Len(t, events[0].Threads, 1)
+	assert.Equal(t, "2", events[0].Threads[0].ID)
+}
+
+func TestWatcherLeak(t *testing.T) {
+	server := serve(t, workers(1), workers(2), workers(3), workers(4), workers(4))
+
+	events := []*core.Event{}
+	watcher := watch.New(watch.URL(server.URL), func(e *core.Event) {
+		events = append(events, e)
+	}, watch.WithGrowthSamples(3))
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, watcher.Poll(context.Background()))
+	}
+	assert.Empty(t, events)
+
+	require.NoError(t, watcher.Poll(context.Background()))
+	require.Len(t, events, 1)
+	assert.Equal(t, watch.Leak, events[0].Panic.Type)
+	assert.Equal(t, "goroutine count grew from 2 to 5 over 3 polls", events[0].Panic.Description)
+	assert.Len(t, events[0].Threads, 5)
+
+	require.NoError(t, watcher.Poll(context.Background()))
+	assert.Len(t, events, 1)
+}
+
+func TestWatcherRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errs := make(chan error, 1)
+	watcher := watch.New(watch.URL(server.URL), func(e *core.Event) {
+		t.Errorf("unexpected event %v", e.Panic)
+	}, watch.WithInterval(10*time.Millisecond), watch.WithErrorHandler(func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}))
+
+	done := make(chan error)
+	go func() {
+		done <- watcher.Run(ctx)
+	}()
+
+	select {
+	case err := <-errs:
+		assert.ErrorContains(t, err, "500")
+	case <-time.After(time.Second):
+		t.Fatal("no error reported")
+	}
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestWatcherRunZeroInterval(t *testing.T) {
+	server := serve(t, workers(1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// A zero interval falls back to the default rather than panicking
+	watcher := watch.New(watch.URL(server.URL), func(e *core.Event) {}, watch.WithInterval(0))
+	assert.ErrorIs(t, watcher.Run(ctx), context.Canceled)
+}
+
+func TestLocal(t *testing.T) {
+	dump, err := watch.Local()(context.Background())
+	require.NoError(t, err)
+	defer dump.Close()
+
+	event, err := core.ParseProfile(dump)
+	require.NoError(t, err)
+	assert.NotEmpty(t, event.Threads)
+	assert.NotEmpty(t, event.Threads[0].ID)
+}